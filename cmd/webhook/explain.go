@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kbsonlong/webhook/pkg/config"
+	"github.com/kbsonlong/webhook/pkg/handler"
+	"github.com/kbsonlong/webhook/pkg/monitor"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+)
+
+// runExplain prints the node pool of every node and the eviction decision of every pod
+func runExplain(args []string) int {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	configFile := fs.String("config", "", "Path of the node pools config file, only the default pool is used when empty")
+	nodesFile := fs.String("nodes", "", "YAML or JSON file with Node, NodeList or List objects (required)")
+	podsFile := fs.String("pods", "", "YAML or JSON file with Pod, PodList or List objects")
+	defaultThreshold := fs.String("default-threshold", "3", "Threshold of the default node pool, absolute or percentage")
+	defaultWindow := fs.Duration("default-window", 5*time.Minute, "Window of the default node pool")
+	armed := fs.Bool("armed", true, "Evaluate as if interception is enabled")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s explain -nodes nodes.yaml [-pods pods.yaml] [-config node-pools.json]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if *nodesFile == "" {
+		fs.Usage()
+		return 2
+	}
+
+	// Keep the output readable, the monitor logs every evaluation
+	klog.LogToStderr(false)
+	klog.SetOutput(io.Discard)
+
+	cfg := &config.Config{
+		DefaultThreshold: intstr.Parse(*defaultThreshold),
+		DefaultWindow:    *defaultWindow,
+	}
+	if *configFile != "" {
+		nodePools, err := config.LoadNodePools(*configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *configFile, err)
+			return 1
+		}
+		cfg.NodePools = nodePools
+	}
+
+	var nodes []*v1.Node
+	if err := decodeManifests(*nodesFile, func(kind string, raw json.RawMessage) error {
+		if kind != "Node" {
+			return fmt.Errorf("unexpected kind %q in nodes file", kind)
+		}
+		node := &v1.Node{}
+		if err := json.Unmarshal(raw, node); err != nil {
+			return err
+		}
+		nodes = append(nodes, node)
+		return nil
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *nodesFile, err)
+		return 1
+	}
+
+	var pods []*v1.Pod
+	if *podsFile != "" {
+		if err := decodeManifests(*podsFile, func(kind string, raw json.RawMessage) error {
+			if kind != "Pod" {
+				return fmt.Errorf("unexpected kind %q in pods file", kind)
+			}
+			pod := &v1.Pod{}
+			if err := json.Unmarshal(raw, pod); err != nil {
+				return err
+			}
+			pods = append(pods, pod)
+			return nil
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *podsFile, err)
+			return 1
+		}
+	}
+
+	// Evaluate offline, the monitor is fed from the manifests instead of an informer
+	callback := handler.NewCallbackHandler()
+	if *armed {
+		callback.Arm(handler.ArmedByCallback, "explain")
+	}
+	nodeMonitor := monitor.NewNodeMonitor(nil, cfg, callback, nil, nil)
+	nodesByName := make(map[string]*v1.Node, len(nodes))
+	for _, node := range nodes {
+		nodesByName[node.Name] = node
+		nodeMonitor.ObserveNode(node)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tPOOL\tNOTREADY")
+	for _, node := range nodes {
+		explanation := nodeMonitor.ExplainEviction(&v1.Pod{}, node)
+		fmt.Fprintf(w, "%s\t%s\t%v\n", node.Name, explanation.Pool, explanation.NodeNotReady)
+	}
+	w.Flush()
+
+	if len(pods) == 0 {
+		return 0
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, "POD\tNODE\tPOOL\tNOTREADY/THRESHOLD\tDECISION\tREASON")
+	for _, pod := range pods {
+		node, found := nodesByName[pod.Spec.NodeName]
+		if !found {
+			node = nil
+		}
+		explanation := nodeMonitor.ExplainEviction(pod, node)
+		decision := "allow"
+		if explanation.Intercept {
+			decision = "intercept"
+		} else if explanation.Audit {
+			decision = "audit"
+		}
+		if pod.Spec.NodeName != "" && !found {
+			explanation.Reason = fmt.Sprintf("node %s not found in nodes file", pod.Spec.NodeName)
+		}
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\t%d/%d\t%s\t%s\n", pod.Namespace, pod.Name, pod.Spec.NodeName,
+			explanation.Pool, explanation.NotReadyCount, explanation.Threshold, decision, explanation.Reason)
+	}
+	w.Flush()
+	return 0
+}
+
+// decodeManifests decodes every object of a multi-document YAML or JSON file,
+// expanding List kinds into their items
+func decodeManifests(path string, fn func(kind string, raw json.RawMessage) error) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+		if err := decodeObject(raw, fn); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeObject dispatches a single object, expanding lists
+func decodeObject(raw json.RawMessage, fn func(kind string, raw json.RawMessage) error) error {
+	var meta struct {
+		Kind  string            `json:"kind"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return err
+	}
+
+	switch meta.Kind {
+	case "List", "NodeList", "PodList":
+		itemKind := ""
+		if meta.Kind != "List" {
+			itemKind = meta.Kind[:len(meta.Kind)-len("List")]
+		}
+		for _, item := range meta.Items {
+			if itemKind != "" {
+				// Items of typed lists may omit the kind
+				if err := fn(itemKind, item); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := decodeObject(item, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fn(meta.Kind, raw)
+	}
+}
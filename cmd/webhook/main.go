@@ -13,10 +13,19 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kbsonlong/webhook/pkg/admin"
+	"github.com/kbsonlong/webhook/pkg/auth"
 	"github.com/kbsonlong/webhook/pkg/config"
+	"github.com/kbsonlong/webhook/pkg/events"
+	"github.com/kbsonlong/webhook/pkg/generated/clientset/versioned"
 	"github.com/kbsonlong/webhook/pkg/handler"
+	"github.com/kbsonlong/webhook/pkg/leader"
 	"github.com/kbsonlong/webhook/pkg/monitor"
+	"github.com/kbsonlong/webhook/pkg/observability"
+	"github.com/kbsonlong/webhook/pkg/policy"
+	"github.com/kbsonlong/webhook/pkg/state"
 	"github.com/kbsonlong/webhook/pkg/webhook"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -24,10 +33,21 @@ import (
 )
 
 var (
-	localMode = flag.Bool("local", false, "Run in local development mode")
+	localMode  = flag.Bool("local", false, "Run in local development mode")
+	strictMode = flag.Bool("strict", false, "Refuse to start when the node pools config is invalid")
 )
 
 func main() {
+	// Offline subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate-config":
+			os.Exit(runValidateConfig(os.Args[2:]))
+		case "explain":
+			os.Exit(runExplain(os.Args[2:]))
+		}
+	}
+
 	// Parse command line flags
 	flag.Parse()
 
@@ -38,6 +58,15 @@ func main() {
 	} else {
 		cfg = config.NewConfig()
 	}
+	if *strictMode {
+		cfg.Strict = true
+	}
+	if cfg.NodePoolsError != nil {
+		if cfg.Strict {
+			klog.Fatalf("Refusing to start in strict mode: %v", cfg.NodePoolsError)
+		}
+		klog.Warningf("Starting without node pools, all nodes use the default pool: %v", cfg.NodePoolsError)
+	}
 
 	// Create Kubernetes client
 	var kubeConfig *rest.Config
@@ -62,28 +91,144 @@ func main() {
 		klog.Fatalf("Failed to create Kubernetes client: %v", err)
 	}
 
-	// Create callback handler
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create callback handler and restore the persisted interception state
 	callbackHandler := handler.NewCallbackHandler()
+	callbackHandler.SetStore(state.NewConfigMapStore(clientset, cfg.Namespace, cfg.StateConfigMap))
+	if err := callbackHandler.Restore(ctx); err != nil {
+		klog.Fatalf("Failed to restore interception state: %v", err)
+	}
+	go callbackHandler.Run(ctx)
+
+	// Create leader elector, only the leader performs cluster-wide side effects
+	var elector *leader.Elector
+	if cfg.LeaderElect {
+		elector, err = leader.NewElector(clientset, cfg.Namespace, cfg.LeaderLease, cfg.PodName)
+		if err != nil {
+			klog.Fatalf("Failed to create leader elector: %v", err)
+		}
+		go elector.Run(ctx)
+	}
+	recorder := events.NewRecorder(clientset, elector)
 
 	// Create node monitor
-	nodeMonitor := monitor.NewNodeMonitor(clientset, cfg, callbackHandler)
+	nodeMonitor := monitor.NewNodeMonitor(clientset, cfg, callbackHandler, recorder, elector)
+
+	// Create webhook handler, evictions only name their pod which is resolved from a pod cache
+	podCache := webhook.NewPodCache(clientset)
+	webhookHandler := webhook.NewWebhook(nodeMonitor, podCache, recorder)
+	if cfg.EvictionRetryAfter > 0 {
+		webhookHandler.SetRetryAfter(cfg.EvictionRetryAfter)
+	}
+
+	// Create config watcher, it also reports whether a valid node pools config is in effect
+	configRef := v1.ObjectReference{
+		Kind:       "ConfigMap",
+		APIVersion: "v1",
+		Namespace:  cfg.Namespace,
+		Name:       cfg.ConfigMapName,
+	}
+	configWatcher := config.NewWatcher(cfg.ConfigMapDir, cfg.NodePoolsError,
+		func(pools []config.NodePoolConfig) {
+			nodeMonitor.SetFilePools(pools)
+			recorder.LeaderEventf(ctx, configRef, v1.EventTypeNormal, "ConfigReloaded",
+				"Reloaded node pools config with %d pools", len(pools))
+		},
+		func(err error) {
+			recorder.LeaderEventf(ctx, configRef, v1.EventTypeWarning, "ConfigReloadFailed",
+				"Failed to reload node pools config, keeping the last valid config: %v", err)
+		})
 
-	// Create webhook handler
-	webhookHandler := webhook.NewWebhook(nodeMonitor, clientset)
+	// Create policy controller
+	var policyController *policy.Controller
+	if cfg.EnablePolicyCRD {
+		policyClient, err := versioned.NewForConfig(kubeConfig)
+		if err != nil {
+			klog.Fatalf("Failed to create policy client: %v", err)
+		}
+		policyController = policy.NewController(policyClient, nodeMonitor, callbackHandler, elector)
+	}
+
+	// Readiness checks, the webhook stays out of the Service while it cannot decide
+	health := observability.NewHealth()
+	health.AddReadyCheck("informer", func() error {
+		if !nodeMonitor.HasSynced() {
+			return fmt.Errorf("node informer has not synced")
+		}
+		return nil
+	})
+	health.AddReadyCheck("pods", func() error {
+		if !podCache.HasSynced() {
+			return fmt.Errorf("pod informer has not synced")
+		}
+		return nil
+	})
+	if cfg.Strict {
+		health.AddReadyCheck("config", configWatcher.LoadError)
+	} else {
+		// Without a valid config every node falls back to the default pool, which still
+		// protects pods, so the webhook stays ready for failurePolicy Fail setups
+		health.AddWarningCheck("config", func() error {
+			if err := configWatcher.LoadError(); err != nil {
+				return fmt.Errorf("using the default node pool until a valid config is loaded: %w", err)
+			}
+			return nil
+		})
+	}
+	if policyController != nil {
+		health.AddReadyCheck("policies", func() error {
+			if !policyController.HasSynced() {
+				return fmt.Errorf("eviction protection policies have not synced")
+			}
+			return nil
+		})
+	}
+	if !*localMode {
+		health.AddReadyCheck("tls", func() error {
+			_, err := tls.LoadX509KeyPair(cfg.CertDir+"/tls.crt", cfg.CertDir+"/tls.key")
+			return err
+		})
+	}
 
 	// Create Gin router
 	router := gin.Default()
 
-	// Add health check endpoint
-	router.GET("/healthz", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	})
+	// Add health check endpoints
+	router.GET("/healthz", gin.WrapF(health.Livez))
+	router.GET("/livez", gin.WrapF(health.Livez))
+	router.GET("/readyz", gin.WrapF(health.Readyz))
 
 	// Add webhook endpoint
 	router.POST("/validate", webhookHandler.HandleAdmission)
 
-	// Add callback endpoint
-	callbackHandler.RegisterRoutes(router)
+	// Create admin server for the callback API, callers are authenticated and authorized
+	// by the apiserver
+	var adminServer *admin.Server
+	if cfg.AdminPort > 0 {
+		if cfg.CallbackAuth {
+			callbackHandler.SetAuthorizer(auth.NewAuthorizer(clientset))
+		} else {
+			klog.Warningf("Callback API authentication is disabled, anyone reaching the admin port can disable interception")
+		}
+		adminRouter := gin.Default()
+		adminRouter.Use(admin.RateLimit(cfg.AdminRateLimit, cfg.AdminRateBurst))
+		callbackHandler.RegisterRoutes(adminRouter)
+
+		adminOptions := admin.Options{
+			Port:              cfg.AdminPort,
+			CertDir:           cfg.AdminCertDir,
+			ClientCAFile:      cfg.AdminClientCAFile,
+			RequireClientCert: cfg.AdminRequireClientCert,
+		}
+		adminServer, err = admin.NewServer(adminOptions, adminRouter)
+		if err != nil {
+			klog.Fatalf("Failed to create admin server: %v", err)
+		}
+	} else {
+		klog.Infof("Admin port is disabled, the callback API is not served")
+	}
 
 	// Create HTTP server
 	server := &http.Server{
@@ -94,15 +239,18 @@ func main() {
 		},
 	}
 
-	// Start node monitor
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Start observability server, kept apart from the TLS admission port
+	var observabilityServer *observability.Server
+	if cfg.MetricsPort > 0 {
+		observabilityServer = observability.NewServer(cfg.MetricsPort, cfg.EnablePprof, health)
+		observabilityServer.Start()
+	}
 
-	if err := nodeMonitor.Start(ctx); err != nil {
-		klog.Fatalf("Failed to start node monitor: %v", err)
+	if adminServer != nil {
+		adminServer.Start()
 	}
 
-	// Start server in a goroutine
+	// Start server in a goroutine, probes are served while the caches sync
 	go func() {
 		klog.Infof("Starting webhook server on port %d", cfg.WebhookPort)
 		if *localMode {
@@ -121,6 +269,30 @@ func main() {
 		}
 	}()
 
+	// Start node monitor
+	if err := nodeMonitor.Start(ctx); err != nil {
+		klog.Fatalf("Failed to start node monitor: %v", err)
+	}
+
+	// Start pod cache
+	if err := podCache.Start(ctx); err != nil {
+		klog.Fatalf("Failed to start pod cache: %v", err)
+	}
+
+	// Watch the node pools config for changes
+	go func() {
+		if err := configWatcher.Run(ctx); err != nil {
+			klog.Errorf("Node pools config hot reload disabled: %v", err)
+		}
+	}()
+
+	// Start policy controller
+	if policyController != nil {
+		if err := policyController.Start(ctx); err != nil {
+			klog.Fatalf("Failed to start policy controller: %v", err)
+		}
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -128,12 +300,23 @@ func main() {
 
 	// Graceful shutdown
 	klog.Info("Shutting down server...")
-	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
 
-	if err := server.Shutdown(ctx); err != nil {
+	if err := server.Shutdown(shutdownCtx); err != nil {
 		klog.Fatalf("Server forced to shutdown: %v", err)
 	}
+	if adminServer != nil {
+		if err := adminServer.Shutdown(shutdownCtx); err != nil {
+			klog.Errorf("Admin server forced to shutdown: %v", err)
+		}
+	}
+	if observabilityServer != nil {
+		if err := observabilityServer.Shutdown(shutdownCtx); err != nil {
+			klog.Errorf("Observability server forced to shutdown: %v", err)
+		}
+	}
 
 	klog.Info("Server exiting")
 }
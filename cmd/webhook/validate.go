@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kbsonlong/webhook/pkg/config"
+)
+
+// runValidateConfig parses and validates a node pools file offline
+func runValidateConfig(args []string) int {
+	fs := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	file := fs.String("file", filepath.Join("/etc/webhook/config", config.NodePoolsFileName), "Path of the node pools config file")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s validate-config [-file node-pools.json]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		*file = fs.Arg(0)
+	}
+
+	nodePools, err := config.LoadNodePools(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *file, err)
+		return 1
+	}
+
+	fmt.Printf("%s: %d node pools are valid\n", *file, len(nodePools))
+	for _, pool := range nodePools {
+		fmt.Printf("  %s: threshold=%s window=%v\n", pool.Name, pool.Threshold.String(), pool.Window.Duration)
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+#!/usr/bin/env bash
+
+# Regenerates the deepcopy functions, clientset, listers and informers of the
+# eviction protection API under pkg/apis and pkg/generated.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(dirname "${BASH_SOURCE[0]}")/..
+CODEGEN_VERSION=${CODEGEN_VERSION:-v0.32.3}
+CODEGEN_PKG=${CODEGEN_PKG:-$(go env GOMODCACHE)/k8s.io/code-generator@${CODEGEN_VERSION}}
+
+if [ ! -d "${CODEGEN_PKG}" ]; then
+  go mod download k8s.io/code-generator@${CODEGEN_VERSION}
+fi
+
+source "${CODEGEN_PKG}/kube_codegen.sh"
+
+kube::codegen::gen_helpers \
+  --boilerplate "${SCRIPT_ROOT}/hack/boilerplate.go.txt" \
+  "${SCRIPT_ROOT}/pkg/apis"
+
+kube::codegen::gen_client \
+  --with-watch \
+  --output-dir "${SCRIPT_ROOT}/pkg/generated" \
+  --output-pkg github.com/kbsonlong/webhook/pkg/generated \
+  --boilerplate "${SCRIPT_ROOT}/hack/boilerplate.go.txt" \
+  "${SCRIPT_ROOT}/pkg/apis"
@@ -0,0 +1,135 @@
+package admin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+)
+
+// Options configures the admin listener serving the callback API
+type Options struct {
+	// Port is the port of the admin listener
+	Port int
+	// CertDir holds tls.crt and tls.key, the listener serves plain HTTP when empty
+	CertDir string
+	// ClientCAFile is a PEM bundle verifying client certificates, client certificates
+	// are not requested when empty
+	ClientCAFile string
+	// RequireClientCert rejects connections without a verified client certificate
+	RequireClientCert bool
+}
+
+// Server serves the callback API apart from the admission listener, so that network
+// policies can admit the apiserver and operator tooling on different ports
+type Server struct {
+	server   *http.Server
+	certFile string
+	keyFile  string
+}
+
+// NewServer creates a new admin Server serving handler with the given options
+func NewServer(opts Options, handler http.Handler) (*Server, error) {
+	s := &Server{
+		server: &http.Server{
+			Addr:    fmt.Sprintf(":%d", opts.Port),
+			Handler: handler,
+		},
+	}
+	if opts.CertDir == "" {
+		if opts.ClientCAFile != "" || opts.RequireClientCert {
+			return nil, fmt.Errorf("client certificates require TLS, set a certificate directory")
+		}
+		return s, nil
+	}
+
+	tlsConfig, err := clientTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	s.server.TLSConfig = tlsConfig
+	s.certFile = filepath.Join(opts.CertDir, "tls.crt")
+	s.keyFile = filepath.Join(opts.CertDir, "tls.key")
+	return s, nil
+}
+
+// clientTLSConfig builds the TLS config verifying client certificates
+func clientTLSConfig(opts Options) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if opts.ClientCAFile == "" {
+		if opts.RequireClientCert {
+			return nil, fmt.Errorf("requiring client certificates needs a client CA file")
+		}
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(opts.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", opts.ClientCAFile)
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	if opts.RequireClientCert {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// Start starts serving in a goroutine
+func (s *Server) Start() {
+	go func() {
+		var err error
+		if s.certFile == "" {
+			klog.Infof("Starting admin server on %s", s.server.Addr)
+			err = s.server.ListenAndServe()
+		} else {
+			klog.Infof("Starting admin server on %s with TLS", s.server.Addr)
+			err = s.server.ListenAndServeTLS(s.certFile, s.keyFile)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			klog.Fatalf("Failed to start admin server: %v", err)
+		}
+	}()
+}
+
+// Shutdown gracefully stops the server
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// RateLimit returns a middleware admitting qps requests per second with bursts of
+// burst requests, the rest is rejected with 429. It admits everything when qps is 0.
+func RateLimit(qps float32, burst int) gin.HandlerFunc {
+	if qps <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	limiter := flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	return func(c *gin.Context) {
+		if !limiter.TryAccept() {
+			klog.V(2).Infof("Rate limited %s %s from %s", c.Request.Method, c.Request.URL.Path, c.ClientIP())
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"status":  "error",
+				"message": "too many requests, retry later",
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestRateLimit(t *testing.T) {
+	router := gin.New()
+	router.Use(RateLimit(0.001, 2))
+	router.GET("/callback/status", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/callback/status", nil))
+		codes = append(codes, rec.Code)
+		if rec.Code == http.StatusTooManyRequests && rec.Header().Get("Retry-After") == "" {
+			t.Errorf("Retry-After header missing")
+		}
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("status codes = %v, want [200 200 429]", codes)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	router := gin.New()
+	router.Use(RateLimit(0, 0))
+	router.GET("/callback/status", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for i := 0; i < 20; i++ {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/callback/status", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status code = %d", i, rec.Code)
+		}
+	}
+}
+
+func TestNewServerOptions(t *testing.T) {
+	dir := t.TempDir()
+	invalidCA := filepath.Join(dir, "invalid-ca.crt")
+	if err := os.WriteFile(invalidCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	validCA := filepath.Join("testdata", "ca.crt")
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantErr    bool
+		clientAuth tls.ClientAuthType
+	}{
+		{name: "plain HTTP", opts: Options{Port: 8444}},
+		{name: "TLS", opts: Options{Port: 8444, CertDir: dir}, clientAuth: tls.NoClientCert},
+		{name: "optional client certificates", opts: Options{Port: 8444, CertDir: dir, ClientCAFile: validCA}, clientAuth: tls.VerifyClientCertIfGiven},
+		{name: "mTLS", opts: Options{Port: 8444, CertDir: dir, ClientCAFile: validCA, RequireClientCert: true}, clientAuth: tls.RequireAndVerifyClientCert},
+		{name: "client CA without TLS", opts: Options{Port: 8444, ClientCAFile: validCA}, wantErr: true},
+		{name: "mTLS without client CA", opts: Options{Port: 8444, CertDir: dir, RequireClientCert: true}, wantErr: true},
+		{name: "invalid client CA", opts: Options{Port: 8444, CertDir: dir, ClientCAFile: invalidCA}, wantErr: true},
+		{name: "missing client CA", opts: Options{Port: 8444, CertDir: dir, ClientCAFile: filepath.Join(dir, "missing.crt")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, err := NewServer(tt.opts, http.NotFoundHandler())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewServer() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewServer() error = %v", err)
+			}
+			if tt.opts.CertDir == "" {
+				if server.server.TLSConfig != nil {
+					t.Errorf("plain HTTP server has a TLS config")
+				}
+				return
+			}
+			if got := server.server.TLSConfig.ClientAuth; got != tt.clientAuth {
+				t.Errorf("ClientAuth = %v, want %v", got, tt.clientAuth)
+			}
+		})
+	}
+}
@@ -0,0 +1,4 @@
+package evictionprotection
+
+// GroupName is the API group of the eviction protection resources
+const GroupName = "evictionprotection.io"
@@ -0,0 +1,5 @@
+// +k8s:deepcopy-gen=package
+// +groupName=evictionprotection.io
+
+// Package v1alpha1 contains the v1alpha1 eviction protection API
+package v1alpha1
@@ -0,0 +1,82 @@
+package v1alpha1
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EvictionProtectionPolicy defines the interception conditions of a node pool
+type EvictionProtectionPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EvictionProtectionPolicySpec   `json:"spec"`
+	Status EvictionProtectionPolicyStatus `json:"status,omitempty"`
+}
+
+// EvictionProtectionPolicySpec is the desired behaviour of a policy
+type EvictionProtectionPolicySpec struct {
+	// NodeSelector selects the nodes belonging to the pool
+	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+	// Threshold is the number of NotReady nodes that triggers interception,
+	// either an absolute number or a percentage of the pool size such as "20%"
+	Threshold intstr.IntOrString `json:"threshold"`
+	// MinThreshold is the lower bound of a percentage threshold, 0 means unbounded
+	// +optional
+	MinThreshold int32 `json:"minThreshold,omitempty"`
+	// MaxThreshold is the upper bound of a percentage threshold, 0 means unbounded
+	// +optional
+	MaxThreshold int32 `json:"maxThreshold,omitempty"`
+	// Window is the time window in which NotReady nodes are counted
+	Window metav1.Duration `json:"window"`
+	// InterceptUpdates lists the classes of pod updates that are intercepted, "deletion"
+	// and "status". Unset uses the global default, an empty list intercepts no updates.
+	// +optional
+	InterceptUpdates []string `json:"interceptUpdates,omitempty"`
+	// AlwaysAllow lists the users, groups and service accounts whose requests are never
+	// intercepted. Unset uses the global default.
+	// +optional
+	AlwaysAllow []rbacv1.Subject `json:"alwaysAllow,omitempty"`
+	// InterceptOnly lists the users, groups and service accounts whose requests are
+	// intercepted exclusively. Unset uses the global default, an empty list intercepts
+	// every requester.
+	// +optional
+	InterceptOnly []rbacv1.Subject `json:"interceptOnly,omitempty"`
+	// Mode is either "enforce", which intercepts evictions, or "audit", which allows them
+	// and only reports what would have been intercepted. Defaults to enforce.
+	// +optional
+	Mode string `json:"mode,omitempty"`
+}
+
+// EvictionProtectionPolicyStatus is the observed state of a policy
+type EvictionProtectionPolicyStatus struct {
+	// ObservedGeneration is the generation of the spec the status was computed from
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// TotalNodes is the number of nodes matching the node selector
+	TotalNodes int32 `json:"totalNodes"`
+	// NotReadyCount is the number of nodes that became NotReady within the window
+	NotReadyCount int32 `json:"notReadyCount"`
+	// Threshold is the effective threshold resolved against the pool size
+	Threshold int32 `json:"threshold"`
+	// Armed reports whether evictions on this pool are currently intercepted
+	Armed bool `json:"armed"`
+	// LastUpdateTime is when the status was last written
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EvictionProtectionPolicyList is a list of EvictionProtectionPolicy
+type EvictionProtectionPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []EvictionProtectionPolicy `json:"items"`
+}
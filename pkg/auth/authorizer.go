@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// Callers of the callback API are authorized against a virtual resource, grant it with
+// an RBAC rule such as apiGroups: ["evictionprotection.io"], resources: ["interception"]
+const (
+	Group    = "evictionprotection.io"
+	Resource = "interception"
+
+	// VerbGet reads the interception status
+	VerbGet = "get"
+	// VerbUpdate enables or disables interception
+	VerbUpdate = "update"
+)
+
+// userKey is the gin context key of the authenticated caller
+const userKey = "evictionprotection.io/user"
+
+// Authorizer authenticates callers by a verified client certificate or by bearer token
+// through TokenReview, and authorizes them through SubjectAccessReview, the way the
+// kube-apiserver delegates to webhooks
+type Authorizer struct {
+	clientset kubernetes.Interface
+}
+
+// NewAuthorizer creates an Authorizer using the given client for the reviews
+func NewAuthorizer(clientset kubernetes.Interface) *Authorizer {
+	return &Authorizer{clientset: clientset}
+}
+
+// Require returns a middleware that only lets callers through that may perform verb
+// on the interception resource
+func (a *Authorizer) Require(verb string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := a.authenticate(c)
+		if !ok {
+			return
+		}
+
+		access, err := a.clientset.AuthorizationV1().SubjectAccessReviews().Create(c.Request.Context(),
+			&authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   user.Username,
+				UID:    user.UID,
+				Groups: user.Groups,
+				Extra:  extra(user.Extra),
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:    Group,
+					Resource: Resource,
+					Verb:     verb,
+				},
+			}}, metav1.CreateOptions{})
+		if err != nil {
+			klog.Errorf("Failed to authorize %s %s for user %s: %v", c.Request.Method, c.Request.URL.Path, user.Username, err)
+			abort(c, http.StatusInternalServerError, "failed to authorize the request")
+			return
+		}
+		if !access.Status.Allowed {
+			klog.Warningf("Denied %s %s to user %s (groups %v): not allowed to %s %s.%s: %s",
+				c.Request.Method, c.Request.URL.Path, user.Username, user.Groups, verb, Resource, Group, access.Status.Reason)
+			abort(c, http.StatusForbidden, "user "+user.Username+" is not allowed to "+verb+" "+Resource+"."+Group)
+			return
+		}
+
+		klog.V(2).Infof("Authorized %s %s for user %s", c.Request.Method, c.Request.URL.Path, user.Username)
+		c.Set(userKey, user.Username)
+		c.Next()
+	}
+}
+
+// authenticate identifies the caller, it aborts the request and returns false when the
+// caller cannot be authenticated
+func (a *Authorizer) authenticate(c *gin.Context) (authenticationv1.UserInfo, bool) {
+	// A certificate verified against the client CA identifies the caller like the
+	// kube-apiserver does: the common name is the user and the organizations its groups
+	if user, ok := certificateUser(c.Request); ok {
+		return user, true
+	}
+
+	token, ok := bearerToken(c.Request)
+	if !ok {
+		klog.Warningf("Denied %s %s from %s: no client certificate or bearer token", c.Request.Method, c.Request.URL.Path, c.ClientIP())
+		c.Header("WWW-Authenticate", `Bearer realm="eviction-protection"`)
+		abort(c, http.StatusUnauthorized, "a client certificate or bearer token is required")
+		return authenticationv1.UserInfo{}, false
+	}
+
+	review, err := a.clientset.AuthenticationV1().TokenReviews().Create(c.Request.Context(),
+		&authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to review token of %s %s from %s: %v", c.Request.Method, c.Request.URL.Path, c.ClientIP(), err)
+		abort(c, http.StatusInternalServerError, "failed to authenticate the request")
+		return authenticationv1.UserInfo{}, false
+	}
+	if !review.Status.Authenticated {
+		klog.Warningf("Denied %s %s from %s: token not authenticated: %s",
+			c.Request.Method, c.Request.URL.Path, c.ClientIP(), review.Status.Error)
+		c.Header("WWW-Authenticate", `Bearer realm="eviction-protection"`)
+		abort(c, http.StatusUnauthorized, "invalid bearer token")
+		return authenticationv1.UserInfo{}, false
+	}
+	return review.Status.User, true
+}
+
+// certificateUser returns the user of a client certificate verified by the TLS handshake
+func certificateUser(req *http.Request) (authenticationv1.UserInfo, bool) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return authenticationv1.UserInfo{}, false
+	}
+	subject := req.TLS.VerifiedChains[0][0].Subject
+	if subject.CommonName == "" {
+		return authenticationv1.UserInfo{}, false
+	}
+	return authenticationv1.UserInfo{Username: subject.CommonName, Groups: subject.Organization}, true
+}
+
+// Username returns the authenticated caller of a request, empty when the request
+// was not authenticated
+func Username(c *gin.Context) string {
+	return c.GetString(userKey)
+}
+
+// bearerToken extracts the bearer token of a request
+func bearerToken(req *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// extra converts the extra attributes of a user for a SubjectAccessReview
+func extra(in map[string]authenticationv1.ExtraValue) map[string]authorizationv1.ExtraValue {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]authorizationv1.ExtraValue, len(in))
+	for key, value := range in {
+		out[key] = authorizationv1.ExtraValue(value)
+	}
+	return out
+}
+
+// abort ends the request with the response format of the callback API
+func abort(c *gin.Context, code int, message string) {
+	c.AbortWithStatusJSON(code, gin.H{
+		"status":  "error",
+		"message": message,
+	})
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestClientset authenticates the token "operator" as alice in group sre and the
+// token "viewer" as bob, and lets only group sre update the interception resource
+func newTestClientset() *fake.Clientset {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		switch review.Spec.Token {
+		case "operator":
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User:          authenticationv1.UserInfo{Username: "alice", Groups: []string{"sre"}},
+			}
+		case "viewer":
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User:          authenticationv1.UserInfo{Username: "bob", Groups: []string{"dev"}},
+			}
+		default:
+			review.Status = authenticationv1.TokenReviewStatus{Error: "token expired"}
+		}
+		return true, review, nil
+	})
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		if attrs.Group != Group || attrs.Resource != Resource {
+			return true, review, nil
+		}
+		for _, group := range review.Spec.Groups {
+			if group == "sre" {
+				review.Status.Allowed = true
+			}
+		}
+		if attrs.Verb == VerbGet {
+			review.Status.Allowed = true
+		}
+		return true, review, nil
+	})
+	return clientset
+}
+
+func TestRequire(t *testing.T) {
+	router := gin.New()
+	authorizer := NewAuthorizer(newTestClientset())
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user": Username(c)})
+	}
+	router.GET("/status", authorizer.Require(VerbGet), handler)
+	router.POST("/disable", authorizer.Require(VerbUpdate), handler)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		want   int
+		user   string
+	}{
+		{name: "no token", method: http.MethodPost, path: "/disable", want: http.StatusUnauthorized},
+		{name: "basic auth", method: http.MethodPost, path: "/disable", header: "Basic YWxpY2U6cGFzcw==", want: http.StatusUnauthorized},
+		{name: "invalid token", method: http.MethodPost, path: "/disable", header: "Bearer expired", want: http.StatusUnauthorized},
+		{name: "not allowed", method: http.MethodPost, path: "/disable", header: "Bearer viewer", want: http.StatusForbidden},
+		{name: "allowed", method: http.MethodPost, path: "/disable", header: "Bearer operator", want: http.StatusOK, user: "alice"},
+		{name: "read only", method: http.MethodGet, path: "/status", header: "bearer viewer", want: http.StatusOK, user: "bob"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status code = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("WWW-Authenticate header missing")
+			}
+			if tt.user == "" {
+				return
+			}
+			var body struct {
+				User string `json:"user"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.User != tt.user {
+				t.Errorf("user = %q, want %q", body.User, tt.user)
+			}
+		})
+	}
+}
+
+func TestRequireClientCertificate(t *testing.T) {
+	router := gin.New()
+	router.POST("/disable", NewAuthorizer(newTestClientset()).Require(VerbUpdate), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user": Username(c)})
+	})
+
+	tests := []struct {
+		name    string
+		subject pkix.Name
+		want    int
+	}{
+		{name: "allowed", subject: pkix.Name{CommonName: "carol", Organization: []string{"sre"}}, want: http.StatusOK},
+		{name: "not allowed", subject: pkix.Name{CommonName: "dave", Organization: []string{"dev"}}, want: http.StatusForbidden},
+		{name: "no common name", subject: pkix.Name{Organization: []string{"sre"}}, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/disable", nil)
+			req.TLS = &tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{{Subject: tt.subject}}},
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status code = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	// Unverified certificates do not identify the caller
+	req := httptest.NewRequest(http.MethodPost, "/disable", nil)
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "carol", Organization: []string{"sre"}}}},
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("unverified certificate: status code = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
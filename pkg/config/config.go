@@ -1,59 +1,273 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 )
 
+// DefaultPoolName 未匹配任何节点池的节点所属的默认节点池名称
+const DefaultPoolName = "default"
+
+const (
+	// UpdateClassDeletion 设置 deletionTimestamp 或 deletionGracePeriodSeconds 的 Pod 更新
+	UpdateClassDeletion = "deletion"
+	// UpdateClassStatus 像节点生命周期控制器一样将 Pod 标记为 NotReady 或 Failed 的状态更新
+	UpdateClassStatus = "status"
+)
+
+const (
+	// PoolModeEnforce 拦截达到阈值的节点池上的驱逐，为默认模式
+	PoolModeEnforce = "enforce"
+	// PoolModeAudit 只记录本应拦截的驱逐并放行，用于在启用保护前评估影响
+	PoolModeAudit = "audit"
+)
+
+// DefaultInterceptUpdates 默认拦截的 Pod 更新类别，其他更新（标签、注解、finalizer 等）不会被拦截
+var DefaultInterceptUpdates = []string{UpdateClassDeletion, UpdateClassStatus}
+
 // NodePoolConfig 节点池配置
 type NodePoolConfig struct {
-	LabelSelector metav1.LabelSelector `json:"labelSelector"` // 节点标签选择器
-	Threshold     int                  `json:"threshold"`     // NotReady节点数量阈值
-	Window        time.Duration        `json:"window"`        // 检测时间窗口
+	Name             string               `json:"name"`                       // 节点池名称，为空时自动生成
+	LabelSelector    metav1.LabelSelector `json:"labelSelector"`              // 节点标签选择器
+	Threshold        intstr.IntOrString   `json:"threshold"`                  // NotReady节点数量阈值，支持绝对值或百分比(如 "20%")
+	MinThreshold     int                  `json:"minThreshold"`               // 百分比阈值换算后的下限，0 表示不限制
+	MaxThreshold     int                  `json:"maxThreshold"`               // 百分比阈值换算后的上限，0 表示不限制
+	Window           Duration             `json:"window"`                     // 检测时间窗口，支持 "300s" 等字符串或整数秒
+	InterceptUpdates []string             `json:"interceptUpdates,omitempty"` // 拦截的 Pod 更新类别，未设置时使用全局配置，空列表表示不拦截更新
+	AlwaysAllow      []rbacv1.Subject     `json:"alwaysAllow,omitempty"`      // 总是允许的请求者，未设置时使用全局配置
+	InterceptOnly    []rbacv1.Subject     `json:"interceptOnly,omitempty"`    // 只拦截这些请求者的请求，未设置时使用全局配置，空列表表示拦截所有请求者
+	Mode             string               `json:"mode,omitempty"`             // enforce 或 audit，为空时使用 enforce
+}
+
+// ResolveThreshold 根据节点池内的节点总数计算实际生效的阈值
+func (p *NodePoolConfig) ResolveThreshold(totalNodes int) (int, error) {
+	threshold, err := intstr.GetScaledValueFromIntOrPercent(&p.Threshold, totalNodes, true)
+	if err != nil {
+		return 0, fmt.Errorf("invalid threshold %q for node pool %s: %w", p.Threshold.String(), p.Name, err)
+	}
+
+	if p.MinThreshold > 0 && threshold < p.MinThreshold {
+		threshold = p.MinThreshold
+	}
+	if p.MaxThreshold > 0 && threshold > p.MaxThreshold {
+		threshold = p.MaxThreshold
+	}
+	// 阈值至少为 1，否则空节点池会一直处于拦截状态
+	if threshold < 1 {
+		threshold = 1
+	}
+	return threshold, nil
 }
 
 // Config 应用配置
 type Config struct {
-	WebhookPort      int              `json:"webhookPort"`
-	CertDir          string           `json:"certDir"`
-	ConfigMapDir     string           `json:"configMapDir"`     // ConfigMap 挂载目录
-	NodePools        []NodePoolConfig `json:"nodePools"`        // 节点池配置列表
-	DefaultThreshold int              `json:"defaultThreshold"` // 默认阈值
-	DefaultWindow    time.Duration    `json:"defaultWindow"`    // 默认时间窗口
+	WebhookPort            int                `json:"webhookPort"`
+	MetricsPort            int                `json:"metricsPort"` // 指标和健康检查端口，使用 HTTP，0 表示不启用
+	EnablePprof            bool               `json:"enablePprof"` // 是否在指标端口上启用 /debug/pprof
+	CertDir                string             `json:"certDir"`
+	ConfigMapDir           string             `json:"configMapDir"`           // ConfigMap 挂载目录
+	ConfigMapName          string             `json:"configMapName"`          // 节点池配置 ConfigMap 名称，用于记录重新加载事件
+	NodePools              []NodePoolConfig   `json:"nodePools"`              // 节点池配置列表
+	DefaultThreshold       intstr.IntOrString `json:"defaultThreshold"`       // 默认阈值
+	DefaultWindow          time.Duration      `json:"defaultWindow"`          // 默认时间窗口
+	AutoArm                bool               `json:"autoArm"`                // 节点池超过阈值时自动启用拦截
+	AutoReleaseAfter       time.Duration      `json:"autoReleaseAfter"`       // 所有节点池恢复到阈值以下多久后自动解除拦截，0 表示由管理员手动解除
+	Namespace              string             `json:"namespace"`              // Webhook 所在的命名空间
+	StateConfigMap         string             `json:"stateConfigMap"`         // 保存拦截状态的 ConfigMap 名称
+	LeaderElect            bool               `json:"leaderElect"`            // 是否启用选主，多副本部署时需要开启
+	LeaderLease            string             `json:"leaderLease"`            // 选主使用的 Lease 名称
+	PodName                string             `json:"podName"`                // 当前副本名称，作为选主身份
+	EnablePolicyCRD        bool               `json:"enablePolicyCRD"`        // 是否从 EvictionProtectionPolicy 资源读取节点池配置
+	Strict                 bool               `json:"strict"`                 // 严格模式，节点池配置无效时拒绝启动
+	InterceptUpdates       []string           `json:"interceptUpdates"`       // 未单独配置的节点池拦截的 Pod 更新类别，nil 表示使用 DefaultInterceptUpdates
+	AlwaysAllow            []rbacv1.Subject   `json:"alwaysAllow"`            // 未单独配置的节点池总是允许的请求者
+	InterceptOnly          []rbacv1.Subject   `json:"interceptOnly"`          // 未单独配置的节点池只拦截这些请求者，为空时拦截所有请求者
+	AdminPort              int                `json:"adminPort"`              // 回调接口的管理端口，0 表示不提供回调接口
+	AdminCertDir           string             `json:"adminCertDir"`           // 管理端口的 TLS 证书目录，为空时使用 HTTP
+	AdminClientCAFile      string             `json:"adminClientCAFile"`      // 校验客户端证书的 CA 文件，为空时不请求客户端证书
+	AdminRequireClientCert bool               `json:"adminRequireClientCert"` // 是否要求客户端证书(mTLS)
+	AdminRateLimit         float32            `json:"adminRateLimit"`         // 管理端口每秒允许的请求数，0 表示不限流
+	AdminRateBurst         int                `json:"adminRateBurst"`         // 管理端口允许的突发请求数
+	CallbackAuth           bool               `json:"callbackAuth"`           // 回调接口是否通过 TokenReview 和 SubjectAccessReview 校验调用者
+	EvictionRetryAfter     time.Duration      `json:"evictionRetryAfter"`     // 被拦截的 pods/eviction 请求建议客户端重试的间隔
+	NodePoolsError         error              `json:"-"`                      // 启动时加载节点池配置的错误
 }
 
 // NewConfig 创建新的配置
 func NewConfig() *Config {
 	port, _ := strconv.Atoi(getEnv("WEBHOOK_PORT", "8443"))
-	threshold, _ := strconv.Atoi(getEnv("NODE_NOTREADY_THRESHOLD", "3"))
+	metricsPort, _ := strconv.Atoi(getEnv("METRICS_PORT", "9090"))
+	enablePprof, _ := strconv.ParseBool(getEnv("ENABLE_PPROF", "false"))
 	window, _ := strconv.Atoi(getEnv("NODE_NOTREADY_WINDOW", "300")) // 默认5分钟
+	autoArm, _ := strconv.ParseBool(getEnv("AUTO_ARM", "false"))
+	autoReleaseAfter, _ := strconv.Atoi(getEnv("AUTO_RELEASE_AFTER", "0"))
+	leaderElect, _ := strconv.ParseBool(getEnv("LEADER_ELECT", "false"))
+	enablePolicyCRD, _ := strconv.ParseBool(getEnv("ENABLE_POLICY_CRD", "false"))
+	strict, _ := strconv.ParseBool(getEnv("STRICT_CONFIG", "false"))
+	evictionRetryAfter, _ := strconv.Atoi(getEnv("EVICTION_RETRY_AFTER", "30"))
+	callbackAuth, _ := strconv.ParseBool(getEnv("CALLBACK_AUTH", "true"))
+	adminPort, _ := strconv.Atoi(getEnv("ADMIN_PORT", "8444"))
+	adminRequireClientCert, _ := strconv.ParseBool(getEnv("ADMIN_REQUIRE_CLIENT_CERT", "false"))
+	adminRateLimit, _ := strconv.ParseFloat(getEnv("ADMIN_RATE_LIMIT", "5"), 32)
+	adminRateBurst, _ := strconv.Atoi(getEnv("ADMIN_RATE_BURST", "10"))
+	certDir := getEnv("CERT_DIR", "/tmp/k8s-webhook-server/serving-certs")
 
-	return &Config{
-		WebhookPort:      port,
-		CertDir:          getEnv("CERT_DIR", "/tmp/k8s-webhook-server/serving-certs"),
-		ConfigMapDir:     getEnv("CONFIG_MAP_DIR", "/etc/webhook/config"),
-		DefaultThreshold: threshold,
-		DefaultWindow:    time.Duration(window) * time.Second,
-		NodePools:        parseNodePoolsConfig(),
+	cfg := &Config{
+		WebhookPort:            port,
+		MetricsPort:            metricsPort,
+		EnablePprof:            enablePprof,
+		CertDir:                certDir,
+		ConfigMapDir:           getEnv("CONFIG_MAP_DIR", "/etc/webhook/config"),
+		ConfigMapName:          getEnv("CONFIG_MAP_NAME", "pod-eviction-protection-config"),
+		DefaultThreshold:       intstr.Parse(getEnv("NODE_NOTREADY_THRESHOLD", "3")),
+		DefaultWindow:          time.Duration(window) * time.Second,
+		AutoArm:                autoArm,
+		AutoReleaseAfter:       time.Duration(autoReleaseAfter) * time.Second,
+		Namespace:              getEnv("POD_NAMESPACE", "default"),
+		StateConfigMap:         getEnv("STATE_CONFIG_MAP", "pod-eviction-protection-state"),
+		LeaderElect:            leaderElect,
+		LeaderLease:            getEnv("LEADER_ELECTION_LEASE", "pod-eviction-protection-leader"),
+		PodName:                getEnv("POD_NAME", hostname()),
+		EnablePolicyCRD:        enablePolicyCRD,
+		Strict:                 strict,
+		InterceptUpdates:       parseUpdateClasses(getEnv("INTERCEPT_UPDATES", strings.Join(DefaultInterceptUpdates, ","))),
+		EvictionRetryAfter:     time.Duration(evictionRetryAfter) * time.Second,
+		CallbackAuth:           callbackAuth,
+		AdminPort:              adminPort,
+		AdminCertDir:           getEnv("ADMIN_CERT_DIR", certDir),
+		AdminClientCAFile:      getEnv("ADMIN_CLIENT_CA_FILE", ""),
+		AdminRequireClientCert: adminRequireClientCert,
+		AdminRateLimit:         float32(adminRateLimit),
+		AdminRateBurst:         adminRateBurst,
+		AlwaysAllow:            parseSubjects("ALWAYS_ALLOW_SUBJECTS", getEnv("ALWAYS_ALLOW_SUBJECTS", "")),
+		InterceptOnly:          parseSubjects("INTERCEPT_ONLY_SUBJECTS", getEnv("INTERCEPT_ONLY_SUBJECTS", "")),
 	}
+	cfg.NodePools, cfg.NodePoolsError = parseNodePoolsConfig(cfg.ConfigMapDir)
+	return cfg
 }
 
 // NewLocalConfig 创建本地开发配置
 func NewLocalConfig() *Config {
-	return &Config{
-		WebhookPort:      8080,
-		CertDir:          "",
-		ConfigMapDir:     "./config",
-		DefaultThreshold: 3,
-		DefaultWindow:    5 * time.Minute,
-		NodePools:        parseNodePoolsConfig(),
+	cfg := &Config{
+		WebhookPort:        8080,
+		MetricsPort:        9090,
+		EnablePprof:        true,
+		CertDir:            "",
+		ConfigMapDir:       getEnv("CONFIG_MAP_DIR", "./config"),
+		ConfigMapName:      getEnv("CONFIG_MAP_NAME", "pod-eviction-protection-config"),
+		DefaultThreshold:   intstr.FromInt32(3),
+		DefaultWindow:      5 * time.Minute,
+		AutoArm:            true,
+		Namespace:          getEnv("POD_NAMESPACE", "default"),
+		StateConfigMap:     getEnv("STATE_CONFIG_MAP", "pod-eviction-protection-state"),
+		LeaderLease:        getEnv("LEADER_ELECTION_LEASE", "pod-eviction-protection-leader"),
+		PodName:            hostname(),
+		EvictionRetryAfter: 30 * time.Second,
+		AdminPort:          8081,
+		AdminRateLimit:     5,
+		AdminRateBurst:     10,
+		AlwaysAllow:        parseSubjects("ALWAYS_ALLOW_SUBJECTS", getEnv("ALWAYS_ALLOW_SUBJECTS", "")),
+		InterceptOnly:      parseSubjects("INTERCEPT_ONLY_SUBJECTS", getEnv("INTERCEPT_ONLY_SUBJECTS", "")),
+	}
+	cfg.NodePools, cfg.NodePoolsError = parseNodePoolsConfig(cfg.ConfigMapDir)
+	return cfg
+}
+
+// parseUpdateClasses 解析逗号分隔的 Pod 更新类别，忽略不支持的类别
+func parseUpdateClasses(value string) []string {
+	classes := []string{}
+	for _, class := range strings.Split(value, ",") {
+		class = strings.TrimSpace(class)
+		if class == "" {
+			continue
+		}
+		if class != UpdateClassDeletion && class != UpdateClassStatus {
+			klog.Errorf("Ignoring unsupported update class %q in INTERCEPT_UPDATES", class)
+			continue
+		}
+		classes = append(classes, class)
 	}
+	return classes
+}
+
+// parseSubjects 解析逗号分隔的请求者列表，格式为 User:<name>、Group:<name> 或
+// ServiceAccount:<namespace>/<name>，无效的值会被忽略
+func parseSubjects(env, value string) []rbacv1.Subject {
+	var subjects []rbacv1.Subject
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		subject, err := parseSubject(entry)
+		if err != nil {
+			klog.Errorf("Ignoring invalid subject %q in %s: %v", entry, env, err)
+			continue
+		}
+		subjects = append(subjects, subject)
+	}
+	return subjects
+}
+
+// parseSubject 解析 User:<name>、Group:<name> 或 ServiceAccount:<namespace>/<name> 格式的请求者
+func parseSubject(value string) (rbacv1.Subject, error) {
+	kind, name, found := strings.Cut(value, ":")
+	if !found || name == "" {
+		return rbacv1.Subject{}, fmt.Errorf("must be Kind:name")
+	}
+	subject := rbacv1.Subject{Kind: kind, Name: name}
+	if kind == rbacv1.ServiceAccountKind {
+		namespace, saName, found := strings.Cut(name, "/")
+		if !found {
+			return rbacv1.Subject{}, fmt.Errorf("service account must be ServiceAccount:<namespace>/<name>")
+		}
+		subject.Namespace, subject.Name = namespace, saName
+	}
+	if errs := validateSubject(subject, field.NewPath("subject")); len(errs) > 0 {
+		return rbacv1.Subject{}, errs.ToAggregate()
+	}
+	return subject, nil
+}
+
+// validateSubject 校验请求者，只支持 User、Group 和 ServiceAccount
+func validateSubject(subject rbacv1.Subject, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch subject.Kind {
+	case rbacv1.UserKind, rbacv1.GroupKind:
+	case rbacv1.ServiceAccountKind:
+		if subject.Namespace == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("namespace"), "required for service accounts"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("kind"), subject.Kind,
+			[]string{rbacv1.UserKind, rbacv1.GroupKind, rbacv1.ServiceAccountKind}))
+	}
+	if subject.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	}
+	return allErrs
+}
+
+// hostname 获取主机名，获取失败时返回固定名称
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "pod-eviction-protection"
+	}
+	return name
 }
 
 // getEnv 获取环境变量，如果不存在则返回默认值
@@ -64,21 +278,140 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-// parseNodePoolsConfig 解析节点池配置
-func parseNodePoolsConfig() []NodePoolConfig {
+// NodePoolsFileName ConfigMap 中节点池配置文件的名称
+const NodePoolsFileName = "node-pools.json"
+
+// parseNodePoolsConfig 解析节点池配置，失败时返回空配置和错误
+func parseNodePoolsConfig(dir string) ([]NodePoolConfig, error) {
 	// 从 ConfigMap 文件读取配置
-	configFile := filepath.Join(getEnv("CONFIG_MAP_DIR", "/etc/webhook/config"), "node-pools.json")
-	data, err := os.ReadFile(configFile)
+	nodePools, err := LoadNodePools(filepath.Join(dir, NodePoolsFileName))
 	if err != nil {
-		klog.Errorf("Failed to read node pools config file: %v", err)
-		return []NodePoolConfig{}
+		klog.Errorf("Failed to load node pools config: %v", err)
+		return []NodePoolConfig{}, err
 	}
+	return nodePools, nil
+}
 
+// LoadNodePools 读取、解析并校验节点池配置文件
+func LoadNodePools(path string) ([]NodePoolConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read node pools config file: %w", err)
+	}
+
+	// 拒绝未知字段，避免拼写错误的字段(如 "treshold")被静默忽略
 	var nodePools []NodePoolConfig
-	if err := json.Unmarshal(data, &nodePools); err != nil {
-		klog.Errorf("Failed to parse node pools config: %v", err)
-		return []NodePoolConfig{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&nodePools); err != nil {
+		return nil, fmt.Errorf("failed to parse node pools config: %w", err)
+	}
+
+	// 为未命名的节点池生成名称
+	for i := range nodePools {
+		if nodePools[i].Name == "" {
+			nodePools[i].Name = fmt.Sprintf("pool-%d", i)
+		}
+	}
+
+	if errs := ValidateNodePools(nodePools); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid node pools config %s: %w", path, errs.ToAggregate())
+	}
+	return nodePools, nil
+}
+
+// ValidateNodePools 校验节点池配置，返回带字段路径的错误列表
+func ValidateNodePools(nodePools []NodePoolConfig) field.ErrorList {
+	var allErrs field.ErrorList
+	names := make(map[string]struct{}, len(nodePools))
+	for i := range nodePools {
+		fldPath := field.NewPath("nodePools").Index(i)
+		allErrs = append(allErrs, ValidateNodePool(&nodePools[i], fldPath)...)
+
+		name := nodePools[i].Name
+		if _, exists := names[name]; exists {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("name"), name))
+		}
+		names[name] = struct{}{}
+	}
+	return allErrs
+}
+
+// ValidateNodePool 校验单个节点池配置
+func ValidateNodePool(pool *NodePoolConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if pool.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	} else if pool.Name == DefaultPoolName {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), pool.Name,
+			"is reserved for the default node pool, which holds nodes not matching any node pool"))
+	}
+
+	selectorPath := fldPath.Child("labelSelector")
+	if len(pool.LabelSelector.MatchLabels) == 0 && len(pool.LabelSelector.MatchExpressions) == 0 {
+		allErrs = append(allErrs, field.Required(selectorPath,
+			"must contain matchLabels or matchExpressions, an empty selector matches every node"))
+	}
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(&pool.LabelSelector,
+		metav1validation.LabelSelectorValidationOptions{}, selectorPath)...)
+
+	thresholdPath := fldPath.Child("threshold")
+	switch pool.Threshold.Type {
+	case intstr.Int:
+		// 未设置的阈值解析为 0，不能与显式的 0 区分，因此整数阈值必须大于 0
+		if pool.Threshold.IntVal == 0 {
+			allErrs = append(allErrs, field.Required(thresholdPath,
+				"must be a positive integer or a percentage such as \"20%\""))
+		} else if pool.Threshold.IntVal < 0 {
+			allErrs = append(allErrs, field.Invalid(thresholdPath, pool.Threshold.IntVal, "must be positive"))
+		}
+	case intstr.String:
+		percent, err := strconv.Atoi(strings.TrimSuffix(pool.Threshold.StrVal, "%"))
+		if !strings.HasSuffix(pool.Threshold.StrVal, "%") || err != nil {
+			allErrs = append(allErrs, field.Invalid(thresholdPath, pool.Threshold.StrVal,
+				"must be an integer or a percentage such as \"20%\""))
+		} else if percent < 0 || percent > 100 {
+			allErrs = append(allErrs, field.Invalid(thresholdPath, pool.Threshold.StrVal,
+				"percentage must be between 0% and 100%"))
+		}
+	}
+
+	if pool.MinThreshold < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minThreshold"), pool.MinThreshold, "must be non-negative"))
+	}
+	if pool.MaxThreshold < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxThreshold"), pool.MaxThreshold, "must be non-negative"))
+	}
+	if pool.MinThreshold > 0 && pool.MaxThreshold > 0 && pool.MinThreshold > pool.MaxThreshold {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxThreshold"), pool.MaxThreshold,
+			"must be greater than or equal to minThreshold"))
+	}
+
+	if pool.Window.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("window"), pool.Window.String(), "must be positive"))
+	}
+
+	for i, class := range pool.InterceptUpdates {
+		if class != UpdateClassDeletion && class != UpdateClassStatus {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("interceptUpdates").Index(i), class,
+				[]string{UpdateClassDeletion, UpdateClassStatus}))
+		}
+	}
+
+	switch pool.Mode {
+	case "", PoolModeEnforce, PoolModeAudit:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), pool.Mode,
+			[]string{PoolModeEnforce, PoolModeAudit}))
+	}
+
+	for i, subject := range pool.AlwaysAllow {
+		allErrs = append(allErrs, validateSubject(subject, fldPath.Child("alwaysAllow").Index(i))...)
+	}
+	for i, subject := range pool.InterceptOnly {
+		allErrs = append(allErrs, validateSubject(subject, fldPath.Child("interceptOnly").Index(i))...)
 	}
 
-	return nodePools
+	return allErrs
 }
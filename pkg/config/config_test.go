@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestParseUpdateClasses(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "deletion,status", want: []string{UpdateClassDeletion, UpdateClassStatus}},
+		{value: " status ", want: []string{UpdateClassStatus}},
+		{value: "deletion,labels", want: []string{UpdateClassDeletion}},
+		{value: "", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := parseUpdateClasses(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseUpdateClasses(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestValidateNodePoolInterceptUpdates(t *testing.T) {
+	pool := NodePoolConfig{
+		Name:             "gpu",
+		LabelSelector:    metav1.LabelSelector{MatchLabels: map[string]string{"pool": "gpu"}},
+		Threshold:        intstr.FromInt(2),
+		Window:           Duration{Duration: 5 * time.Minute},
+		InterceptUpdates: []string{UpdateClassDeletion, "labels"},
+	}
+
+	errs := ValidateNodePool(&pool, field.NewPath("nodePools").Index(0))
+	if len(errs) != 1 {
+		t.Fatalf("errors = %v, want exactly one", errs)
+	}
+	if errs[0].Type != field.ErrorTypeNotSupported || errs[0].Field != "nodePools[0].interceptUpdates[1]" {
+		t.Errorf("error = %v", errs[0])
+	}
+
+	pool.InterceptUpdates = []string{}
+	if errs := ValidateNodePool(&pool, field.NewPath("nodePools").Index(0)); len(errs) != 0 {
+		t.Errorf("empty interceptUpdates rejected: %v", errs)
+	}
+}
+
+func TestParseSubjects(t *testing.T) {
+	got := parseSubjects("TEST", "Group:system:masters, ServiceAccount:kube-system/node-controller,User:alice,Role:admin,ServiceAccount:descheduler")
+	want := []rbacv1.Subject{
+		{Kind: rbacv1.GroupKind, Name: "system:masters"},
+		{Kind: rbacv1.ServiceAccountKind, Namespace: "kube-system", Name: "node-controller"},
+		{Kind: rbacv1.UserKind, Name: "alice"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSubjects() = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidateNodePoolModeAndSubjects(t *testing.T) {
+	pool := NodePoolConfig{
+		Name:          "gpu",
+		LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pool": "gpu"}},
+		Threshold:     intstr.FromInt(2),
+		Window:        Duration{Duration: 5 * time.Minute},
+		AlwaysAllow:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "descheduler"}},
+		InterceptOnly: []rbacv1.Subject{{Kind: "Role", Name: "admin"}},
+		Mode:          "warn",
+	}
+
+	errs := ValidateNodePool(&pool, field.NewPath("nodePools").Index(0))
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	want := []string{"nodePools[0].mode", "nodePools[0].alwaysAllow[0].namespace", "nodePools[0].interceptOnly[0].kind"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("error fields = %v, want %v", fields, want)
+	}
+}
+
+func TestLoadNodePoolsRejectsMissingThreshold(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			config:  `[{"name": "gpu", "labelSelector": {"matchLabels": {"pool": "gpu"}}, "threshold": 2, "window": "300s"}]`,
+			wantErr: "",
+		},
+		{
+			name:    "missing threshold",
+			config:  `[{"name": "gpu", "labelSelector": {"matchLabels": {"pool": "gpu"}}, "window": "300s"}]`,
+			wantErr: "nodePools[0].threshold: Required value",
+		},
+		{
+			name:    "misspelled threshold",
+			config:  `[{"name": "gpu", "labelSelector": {"matchLabels": {"pool": "gpu"}}, "treshold": 5, "window": "300s"}]`,
+			wantErr: `unknown field "treshold"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), NodePoolsFileName)
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadNodePools(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadNodePools() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadNodePools() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNodePoolsReservedName(t *testing.T) {
+	nodePools := []NodePoolConfig{{
+		Name:          DefaultPoolName,
+		LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pool": "gpu"}},
+		Threshold:     intstr.FromInt(2),
+		Window:        Duration{Duration: 5 * time.Minute},
+	}}
+
+	errs := ValidateNodePools(nodePools)
+	if len(errs) != 1 || errs[0].Type != field.ErrorTypeInvalid || errs[0].Field != "nodePools[0].name" {
+		t.Errorf("errors = %v, want the name rejected", errs)
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration 时间长度，JSON 中支持 Go duration 字符串(如 "300s"、"5m")或整数秒
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON 解析 duration 字符串或整数秒
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		d.Duration = parsed
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var n json.Number
+	if err := decoder.Decode(&n); err != nil {
+		return fmt.Errorf("invalid duration %s: must be a duration string or integer seconds", data)
+	}
+	seconds, err := n.Int64()
+	if err != nil {
+		return fmt.Errorf("invalid duration %s: must be a duration string or integer seconds", data)
+	}
+	d.Duration = time.Duration(seconds) * time.Second
+	return nil
+}
+
+// MarshalJSON 输出 duration 字符串
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Duration.String())
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/klog/v2"
+)
+
+var (
+	configReloadTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "config_reload_total",
+		Help: "Total number of node pool config reloads by result",
+	}, []string{"result"})
+	configLastReloadSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "config_last_reload_success_timestamp_seconds",
+		Help: "Timestamp of the last successful node pool config reload",
+	})
+)
+
+// reloadDebounce 合并短时间内的多次文件变化
+const reloadDebounce = 500 * time.Millisecond
+
+// Watcher 监听 ConfigMap 挂载目录，节点池配置变化时重新加载
+type Watcher struct {
+	dir      string
+	onReload func([]NodePoolConfig)
+	onError  func(error)
+	lastData []byte
+
+	mu      sync.RWMutex
+	loadErr error // 尚未加载到有效配置时的错误
+}
+
+// NewWatcher 创建一个新的 Watcher，配置有效时调用 onReload，无效时调用 onError 并保留上一次有效的配置
+// initialErr 为启动时加载配置的错误，为 nil 表示启动时已加载有效配置
+func NewWatcher(dir string, initialErr error, onReload func([]NodePoolConfig), onError func(error)) *Watcher {
+	return &Watcher{
+		dir:      dir,
+		onReload: onReload,
+		onError:  onError,
+		loadErr:  initialErr,
+	}
+}
+
+// LoadError 返回尚未加载到有效配置的原因，已有有效配置生效时返回 nil
+// 有效配置生效后的重新加载失败不会影响返回值，因为上一次有效的配置仍在使用
+func (w *Watcher) LoadError() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.loadErr
+}
+
+// Run 监听配置目录直到 ctx 结束
+// kubelet 更新 ConfigMap 时会原子替换 ..data 软链接，因此监听目录而不是文件本身
+func (w *Watcher) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(w.dir); err != nil {
+		return fmt.Errorf("failed to watch config directory %s: %w", w.dir, err)
+	}
+	// 记录启动时的配置内容，避免重复加载
+	w.lastData, _ = os.ReadFile(w.path())
+	klog.Infof("Watching node pools config in %s", w.dir)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			name := filepath.Base(event.Name)
+			if name != "..data" && name != NodePoolsFileName {
+				continue
+			}
+			klog.V(2).Infof("Config directory event: %s", event)
+			debounce = time.After(reloadDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			klog.Errorf("Config watcher error: %v", err)
+		case <-debounce:
+			debounce = nil
+			w.reload()
+		}
+	}
+}
+
+// reload 重新加载配置文件
+func (w *Watcher) reload() {
+	data, err := os.ReadFile(w.path())
+	if err == nil && bytes.Equal(data, w.lastData) {
+		return
+	}
+
+	nodePools, err := LoadNodePools(w.path())
+	if err != nil {
+		configReloadTotal.WithLabelValues("failure").Inc()
+		klog.Errorf("Failed to reload node pools config, keeping the last valid config: %v", err)
+		w.onError(err)
+		return
+	}
+
+	w.lastData = data
+	w.mu.Lock()
+	w.loadErr = nil
+	w.mu.Unlock()
+	configReloadTotal.WithLabelValues("success").Inc()
+	configLastReloadSuccess.SetToCurrentTime()
+	klog.Infof("Reloaded node pools config with %d pools", len(nodePools))
+	w.onReload(nodePools)
+}
+
+// path 返回节点池配置文件路径
+func (w *Watcher) path() string {
+	return filepath.Join(w.dir, NodePoolsFileName)
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kbsonlong/webhook/pkg/leader"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// Component is the event source component of the webhook
+const Component = "pod-eviction-protection"
+
+// Recorder creates Kubernetes events on behalf of the webhook
+type Recorder struct {
+	clientset kubernetes.Interface
+	elector   *leader.Elector
+}
+
+// NewRecorder creates a new Recorder, elector may be nil when leader election is disabled.
+// A nil Recorder drops all events.
+func NewRecorder(clientset kubernetes.Interface, elector *leader.Elector) *Recorder {
+	return &Recorder{
+		clientset: clientset,
+		elector:   elector,
+	}
+}
+
+// Eventf creates an event for the referenced object. It is meant for side effects
+// of a single request, which only the replica serving the request observes.
+func (r *Recorder) Eventf(ctx context.Context, ref v1.ObjectReference, eventType, reason, messageFmt string, args ...interface{}) {
+	if r == nil {
+		return
+	}
+	message := fmt.Sprintf(messageFmt, args...)
+	now := metav1.NewTime(time.Now())
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: Component + "-",
+			Namespace:    ref.Namespace,
+		},
+		InvolvedObject: ref,
+		Reason:         reason,
+		Message:        message,
+		Source: v1.EventSource{
+			Component: Component,
+		},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           eventType,
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+		event.Namespace = namespace
+	}
+
+	if _, err := r.clientset.CoreV1().Events(namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		klog.Errorf("Failed to create %s event for %s %s/%s: %v", reason, ref.Kind, ref.Namespace, ref.Name, err)
+		return
+	}
+	klog.Infof("Created %s event for %s %s/%s", reason, ref.Kind, ref.Namespace, ref.Name)
+}
+
+// LeaderEventf creates an event only on the leader replica. It is meant for
+// cluster-wide state changes that every replica observes.
+func (r *Recorder) LeaderEventf(ctx context.Context, ref v1.ObjectReference, eventType, reason, messageFmt string, args ...interface{}) {
+	if r == nil || !r.elector.IsLeader() {
+		klog.V(4).Infof("Skipping %s event, this replica is not the leader", reason)
+		return
+	}
+	r.Eventf(ctx, ref, eventType, reason, messageFmt, args...)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright The pod-eviction-protection Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	context "context"
+	time "time"
+
+	apisevictionprotectionv1alpha1 "github.com/kbsonlong/webhook/pkg/apis/evictionprotection/v1alpha1"
+	versioned "github.com/kbsonlong/webhook/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/kbsonlong/webhook/pkg/generated/informers/externalversions/internalinterfaces"
+	evictionprotectionv1alpha1 "github.com/kbsonlong/webhook/pkg/generated/listers/evictionprotection/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// EvictionProtectionPolicyInformer provides access to a shared informer and lister for
+// EvictionProtectionPolicies.
+type EvictionProtectionPolicyInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() evictionprotectionv1alpha1.EvictionProtectionPolicyLister
+}
+
+type evictionProtectionPolicyInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewEvictionProtectionPolicyInformer constructs a new informer for EvictionProtectionPolicy type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewEvictionProtectionPolicyInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredEvictionProtectionPolicyInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredEvictionProtectionPolicyInformer constructs a new informer for EvictionProtectionPolicy type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredEvictionProtectionPolicyInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.EvictionprotectionV1alpha1().EvictionProtectionPolicies().List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.EvictionprotectionV1alpha1().EvictionProtectionPolicies().Watch(context.TODO(), options)
+			},
+		},
+		&apisevictionprotectionv1alpha1.EvictionProtectionPolicy{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *evictionProtectionPolicyInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredEvictionProtectionPolicyInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *evictionProtectionPolicyInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&apisevictionprotectionv1alpha1.EvictionProtectionPolicy{}, f.defaultInformer)
+}
+
+func (f *evictionProtectionPolicyInformer) Lister() evictionprotectionv1alpha1.EvictionProtectionPolicyLister {
+	return evictionprotectionv1alpha1.NewEvictionProtectionPolicyLister(f.Informer().GetIndexer())
+}
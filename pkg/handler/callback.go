@@ -1,45 +1,144 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kbsonlong/webhook/pkg/auth"
+	"github.com/kbsonlong/webhook/pkg/state"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
+// PoolStatus 节点池状态
+type PoolStatus struct {
+	NotReadyNodes []string `json:"notReadyNodes"`           // 节点池内的 NotReady 节点
+	NotReadyCount int      `json:"notReadyCount"`           // 时间窗口内的 NotReady 节点数量
+	TotalNodes    int      `json:"totalNodes"`              // 节点池内的节点总数
+	Threshold     int      `json:"threshold"`               // 实际生效的拦截阈值
+	Mode          string   `json:"mode"`                    // 节点池的模式，enforce 或 audit
+	Armed         bool     `json:"armed"`                   // 节点池上的驱逐当前是否被拦截，考虑审计模式和节点池的解除拦截
+	ReleasedNodes []string `json:"releasedNodes,omitempty"` // 通过节点注解解除拦截的节点
+}
+
+const (
+	// ArmedByCallback 通过回调接口启用拦截
+	ArmedByCallback = "callback"
+	// ArmedByAuto 节点池超过阈值时自动启用拦截
+	ArmedByAuto = "auto"
+	// ArmedByExpiry 限时禁用拦截到期后自动重新启用拦截
+	ArmedByExpiry = "expiry"
+)
+
+var interceptionArmed = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "interception_armed",
+	Help: "Whether eviction interception is currently armed (1) or not (0)",
+})
+
+// PoolStatusFunc 返回各节点池当前状态
+type PoolStatusFunc func() map[string]PoolStatus
+
 // CallbackHandler 处理解除拦截的回调请求
 type CallbackHandler struct {
 	mu            sync.RWMutex
 	intercepting  bool
+	armedAt       time.Time     // 最近一次启用拦截的时间
+	armedBy       string        // 启用拦截的来源
+	armedReason   string        // 启用拦截的原因
+	updatedAt     time.Time     // 最近一次修改拦截状态的时间
+	disabled      disableRecord // 最近一次通过回调接口禁用拦截的记录，启用拦截时清空
 	notReadyNodes map[string]struct{}
+	releases      map[releaseKey]state.Release // 单独解除拦截的范围
+	poolStatus    PoolStatusFunc
+	store         state.Store
+	dirty         chan struct{} // 状态变化通知，由 Run 负责持久化
+	authorizer    *auth.Authorizer
+	clock         clock.PassiveClock // 判断禁用和解除拦截是否到期
+}
+
+// disableRecord 通过回调接口禁用拦截的记录
+type disableRecord struct {
+	at     time.Time
+	by     string
+	reason string
+	until  time.Time // 到期时间，零值表示一直禁用
 }
 
 // NewCallbackHandler 创建一个新的 CallbackHandler
 func NewCallbackHandler() *CallbackHandler {
 	return &CallbackHandler{
 		notReadyNodes: make(map[string]struct{}),
+		releases:      make(map[releaseKey]state.Release),
+		dirty:         make(chan struct{}, 1),
+		clock:         clock.RealClock{},
 	}
 }
 
+// SetClock 替换判断到期使用的时钟，用于测试
+func (h *CallbackHandler) SetClock(clock clock.PassiveClock) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clock = clock
+}
+
+// SetAuthorizer 设置回调接口的认证鉴权，需要在 RegisterRoutes 之前调用，未设置时不做校验
+func (h *CallbackHandler) SetAuthorizer(authorizer *auth.Authorizer) {
+	h.authorizer = authorizer
+}
+
 // RegisterRoutes 注册回调路由
 func (h *CallbackHandler) RegisterRoutes(router *gin.Engine) {
-	router.POST("/callback/disable-interception", h.DisableInterception)
-	router.POST("/callback/enable-interception", h.EnableInterception)
-	router.GET("/callback/status", h.GetStatus)
+	router.POST("/callback/disable-interception", h.require(auth.VerbUpdate), h.DisableInterception)
+	router.POST("/callback/enable-interception", h.require(auth.VerbUpdate), h.EnableInterception)
+	router.POST("/callback/release/:scope/:name", h.require(auth.VerbUpdate), h.ReleaseScope)
+	router.POST("/callback/protect/:scope/:name", h.require(auth.VerbUpdate), h.ProtectScope)
+	router.GET("/callback/status", h.require(auth.VerbGet), h.GetStatus)
+}
+
+// require 返回校验调用者是否有权限执行 verb 的中间件
+func (h *CallbackHandler) require(verb string) gin.HandlerFunc {
+	if h.authorizer == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return h.authorizer.Require(verb)
+}
+
+// caller 返回回调请求的调用者，用于日志和拦截原因
+func caller(c *gin.Context) string {
+	if username := auth.Username(c); username != "" {
+		return username
+	}
+	return c.ClientIP()
 }
 
-// DisableInterception 禁用拦截
+// DisableInterception 禁用拦截，请求体可以指定有效期和原因，到期后自动重新启用拦截。
+// NotReady 节点仍由 NodeMonitor 维护，不会被清空
 func (h *CallbackHandler) DisableInterception(c *gin.Context) {
+	req, ok := bindReleaseRequest(c)
+	if !ok {
+		return
+	}
+	by := caller(c)
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	h.intercepting = false
-	h.notReadyNodes = make(map[string]struct{})
-	klog.Infof("Interception disabled via callback")
+	h.disarm()
+	h.disabled = disableRecord{at: h.clock.Now(), by: by, reason: req.Reason}
+	message := "Interception disabled successfully"
+	if req.TTL.Duration > 0 {
+		h.disabled.until = h.disabled.at.Add(req.TTL.Duration)
+		message = fmt.Sprintf("Interception disabled successfully until %s", h.disabled.until.UTC().Format(time.RFC3339))
+	}
+	klog.Infof("Interception disabled via callback by %s for %s: %s", by, ttlName(req.TTL.Duration), req.Reason)
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
-		"message": "Interception disabled successfully",
+		"message": message,
 	})
 }
 
@@ -48,43 +147,153 @@ func (h *CallbackHandler) EnableInterception(c *gin.Context) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	h.intercepting = true
-	klog.Infof("Interception enabled via callback")
+	h.arm(ArmedByCallback, "enabled via callback by "+caller(c))
+	klog.Infof("Interception enabled via callback by %s", caller(c))
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "Interception enabled successfully",
 	})
 }
 
+// SetPoolStatusFunc 设置节点池状态来源
+func (h *CallbackHandler) SetPoolStatusFunc(fn PoolStatusFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.poolStatus = fn
+}
+
 // GetStatus 获取当前拦截状态
 func (h *CallbackHandler) GetStatus(c *gin.Context) {
 	h.mu.RLock()
-	defer h.mu.RUnlock()
+	intercepting := h.isIntercepting(h.clock.Now())
+	armed := gin.H{
+		"armedBy":     h.armedBy,
+		"armedReason": h.armedReason,
+	}
+	if !h.armedAt.IsZero() {
+		armed["armedAt"] = h.armedAt
+	}
+	now := h.clock.Now()
+	var disabled gin.H
+	if !h.isIntercepting(now) && h.disabled.by != "" {
+		disabled = gin.H{
+			"disabledAt":     h.disabled.at,
+			"disabledBy":     h.disabled.by,
+			"disabledReason": h.disabled.reason,
+		}
+		if !h.disabled.until.IsZero() {
+			disabled["expiresAt"] = h.disabled.until
+			disabled["remaining"] = remaining(h.disabled.until, now)
+		}
+	}
+	notReadyNodes := h.getNotReadyNodeNames()
+	releases := h.releaseStatuses(now)
+	poolStatus := h.poolStatus
+	h.mu.RUnlock()
+
+	// 节点池状态在锁外获取，避免与 NodeMonitor 的锁形成死锁
+	pools := map[string]PoolStatus{}
+	if poolStatus != nil {
+		pools = poolStatus()
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 		"data": gin.H{
-			"intercepting":  h.intercepting,
-			"notReadyNodes": h.getNotReadyNodeNames(),
+			"intercepting":  intercepting,
+			"armed":         armed,
+			"disabled":      disabled,
+			"notReadyNodes": notReadyNodes,
+			"releases":      releases,
+			"pools":         pools,
 		},
 	})
 }
 
-// IsIntercepting 检查是否正在拦截
+// IsIntercepting 检查是否正在拦截，限时禁用到期后即视为拦截，不等待 Expire 重新启用
 func (h *CallbackHandler) IsIntercepting() bool {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	return h.intercepting
+	return h.isIntercepting(h.clock.Now())
+}
+
+// isIntercepting 检查在 now 时是否拦截，调用方需持有锁
+func (h *CallbackHandler) isIntercepting(now time.Time) bool {
+	return h.intercepting || h.disableExpired(now)
+}
+
+// disableExpired 检查限时禁用是否已经到期，调用方需持有锁
+func (h *CallbackHandler) disableExpired(now time.Time) bool {
+	return !h.intercepting && !h.disabled.until.IsZero() && !now.Before(h.disabled.until)
+}
+
+// Arm 启用拦截并记录来源和原因，返回拦截状态是否发生变化
+func (h *CallbackHandler) Arm(by, reason string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.intercepting {
+		return false
+	}
+	h.arm(by, reason)
+	return true
+}
+
+// Disarm 解除拦截，返回拦截状态是否发生变化
+func (h *CallbackHandler) Disarm() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.intercepting {
+		return false
+	}
+	h.disarm()
+	return true
+}
+
+// ArmedBy 返回当前拦截的启用来源，未拦截时返回空字符串
+func (h *CallbackHandler) ArmedBy() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if !h.intercepting {
+		return ""
+	}
+	return h.armedBy
+}
+
+// arm 启用拦截，调用方需持有锁
+func (h *CallbackHandler) arm(by, reason string) {
+	now := h.clock.Now()
+	h.intercepting = true
+	h.armedAt = now
+	h.armedBy = by
+	h.armedReason = reason
+	h.disabled = disableRecord{}
+	h.updatedAt = now
+	interceptionArmed.Set(1)
+	h.markDirty()
+}
+
+// disarm 解除拦截，调用方需持有锁
+func (h *CallbackHandler) disarm() {
+	h.intercepting = false
+	h.armedAt = time.Time{}
+	h.armedBy = ""
+	h.armedReason = ""
+	h.disabled = disableRecord{}
+	h.updatedAt = h.clock.Now()
+	interceptionArmed.Set(0)
+	h.markDirty()
 }
 
 // AddNotReadyNode 添加 NotReady 节点
+// NotReady 节点由每个副本的 NodeMonitor 维护，Restore 也不会恢复，因此变化不触发持久化，
+// 避免节点故障风暴期间每个副本都写入状态 ConfigMap
 func (h *CallbackHandler) AddNotReadyNode(nodeName string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	h.notReadyNodes[nodeName] = struct{}{}
 }
 
-// RemoveNotReadyNode 移除 NotReady 节点
+// RemoveNotReadyNode 移除 NotReady 节点，与 AddNotReadyNode 一样不触发持久化
 func (h *CallbackHandler) RemoveNotReadyNode(nodeName string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
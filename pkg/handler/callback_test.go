@@ -0,0 +1,341 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kbsonlong/webhook/pkg/state"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// memoryStore keeps the interception state in memory
+type memoryStore struct {
+	mu    sync.Mutex
+	state *state.State
+	saved chan *state.State
+}
+
+func newMemoryStore(st *state.State) *memoryStore {
+	return &memoryStore{state: st, saved: make(chan *state.State, 16)}
+}
+
+func (s *memoryStore) Load(context.Context) (*state.State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, nil
+}
+
+func (s *memoryStore) Save(_ context.Context, st *state.State) error {
+	s.mu.Lock()
+	s.state = st
+	s.mu.Unlock()
+	s.saved <- st
+	return nil
+}
+
+func (s *memoryStore) Watch(ctx context.Context, _ func(*state.State)) {
+	<-ctx.Done()
+}
+
+// statusResponse is the body of GET /callback/status
+type statusResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Intercepting bool `json:"intercepting"`
+		Armed        struct {
+			ArmedBy     string `json:"armedBy"`
+			ArmedReason string `json:"armedReason"`
+		} `json:"armed"`
+		Disabled *struct {
+			DisabledBy     string    `json:"disabledBy"`
+			DisabledReason string    `json:"disabledReason"`
+			ExpiresAt      time.Time `json:"expiresAt"`
+			Remaining      string    `json:"remaining"`
+		} `json:"disabled"`
+		NotReadyNodes []string              `json:"notReadyNodes"`
+		Releases      []releaseStatus       `json:"releases"`
+		Pools         map[string]PoolStatus `json:"pools"`
+	} `json:"data"`
+}
+
+// do sends a request to the callback routes
+func do(t *testing.T, h *CallbackHandler, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	return doBody(t, h, method, path, "")
+}
+
+// doBody sends a request with a JSON body to the callback routes
+func doBody(t *testing.T, h *CallbackHandler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	h.RegisterRoutes(router)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// getStatus returns the decoded status response
+func getStatus(t *testing.T, h *CallbackHandler) statusResponse {
+	t.Helper()
+	rec := do(t, h, http.MethodGet, "/callback/status")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp statusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	return resp
+}
+
+func TestEnableAndDisableInterception(t *testing.T) {
+	h := NewCallbackHandler()
+	h.AddNotReadyNode("node-1")
+
+	if rec := do(t, h, http.MethodPost, "/callback/enable-interception"); rec.Code != http.StatusOK {
+		t.Fatalf("enable: status code = %d", rec.Code)
+	}
+	status := getStatus(t, h)
+	if !status.Data.Intercepting || status.Data.Armed.ArmedBy != ArmedByCallback {
+		t.Errorf("after enable: intercepting = %v, armedBy = %q", status.Data.Intercepting, status.Data.Armed.ArmedBy)
+	}
+	if len(status.Data.NotReadyNodes) != 1 || status.Data.NotReadyNodes[0] != "node-1" {
+		t.Errorf("NotReady nodes = %v, want [node-1]", status.Data.NotReadyNodes)
+	}
+
+	if rec := do(t, h, http.MethodPost, "/callback/disable-interception"); rec.Code != http.StatusOK {
+		t.Fatalf("disable: status code = %d", rec.Code)
+	}
+	status = getStatus(t, h)
+	if status.Data.Intercepting || status.Data.Armed.ArmedBy != "" {
+		t.Errorf("after disable: intercepting = %v, armedBy = %q", status.Data.Intercepting, status.Data.Armed.ArmedBy)
+	}
+	if len(status.Data.NotReadyNodes) != 1 {
+		t.Errorf("after disable: NotReady nodes = %v, want them kept", status.Data.NotReadyNodes)
+	}
+}
+
+func TestReleaseAndProtect(t *testing.T) {
+	h := NewCallbackHandler()
+	h.Arm(ArmedByCallback, "storm")
+
+	for _, path := range []string{"/callback/release/node/node-1", "/callback/release/namespace/batch", "/callback/release/node/node-1"} {
+		if rec := do(t, h, http.MethodPost, path); rec.Code != http.StatusOK {
+			t.Fatalf("%s: status code = %d", path, rec.Code)
+		}
+	}
+	if rec := do(t, h, http.MethodPost, "/callback/release/cluster/all"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid scope: status code = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	status := getStatus(t, h)
+	if !status.Data.Intercepting {
+		t.Error("a release disabled interception globally")
+	}
+	releases := status.Data.Releases
+	if len(releases) != 2 || releases[0].Scope != state.ScopeNamespace || releases[1].Name != "node-1" {
+		t.Fatalf("releases = %+v, want namespace batch and node node-1", releases)
+	}
+	if releases[1].ReleasedBy == "" || releases[1].ReleasedAt.IsZero() {
+		t.Errorf("release = %+v, want the caller and time recorded", releases[1])
+	}
+
+	if rec := do(t, h, http.MethodPost, "/callback/protect/node/node-1"); rec.Code != http.StatusOK {
+		t.Fatalf("protect: status code = %d", rec.Code)
+	}
+	if _, released := h.Released(state.ScopeNode, "node-1"); released {
+		t.Error("node-1 is still released after protect")
+	}
+	if _, released := h.Released(state.ScopeNamespace, "batch"); !released {
+		t.Error("namespace batch is no longer released")
+	}
+}
+
+func TestStatusIncludesPools(t *testing.T) {
+	h := NewCallbackHandler()
+	h.SetPoolStatusFunc(func() map[string]PoolStatus {
+		return map[string]PoolStatus{
+			"gpu": {NotReadyNodes: []string{"gpu-1"}, NotReadyCount: 1, TotalNodes: 4, Threshold: 2},
+		}
+	})
+
+	pools := getStatus(t, h).Data.Pools
+	if got := pools["gpu"]; got.TotalNodes != 4 || got.Threshold != 2 || got.NotReadyCount != 1 {
+		t.Errorf("pool gpu = %+v", got)
+	}
+}
+
+func TestArmAndDisarm(t *testing.T) {
+	h := NewCallbackHandler()
+
+	if !h.Arm(ArmedByAuto, "storm") {
+		t.Fatal("Arm reported no change on a disarmed handler")
+	}
+	if h.Arm(ArmedByCallback, "again") {
+		t.Error("Arm reported a change on an armed handler")
+	}
+	if h.ArmedBy() != ArmedByAuto {
+		t.Errorf("armedBy = %q, want %q", h.ArmedBy(), ArmedByAuto)
+	}
+	if !h.Disarm() {
+		t.Fatal("Disarm reported no change on an armed handler")
+	}
+	if h.Disarm() {
+		t.Error("Disarm reported a change on a disarmed handler")
+	}
+	if h.ArmedBy() != "" {
+		t.Errorf("armedBy = %q after disarm, want empty", h.ArmedBy())
+	}
+}
+
+func TestRestoreAndPersist(t *testing.T) {
+	store := newMemoryStore(&state.State{
+		Intercepting: true,
+		ArmedBy:      ArmedByAuto,
+		ArmedReason:  "storm",
+		UpdatedAt:    time.Now().Add(-time.Minute),
+	})
+	h := NewCallbackHandler()
+	h.SetStore(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := h.Restore(ctx); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if !h.IsIntercepting() || h.ArmedBy() != ArmedByAuto {
+		t.Fatalf("restored intercepting = %v, armedBy = %q", h.IsIntercepting(), h.ArmedBy())
+	}
+
+	go h.Run(ctx)
+	h.Disarm()
+	select {
+	case st := <-store.saved:
+		if st.Intercepting {
+			t.Error("persisted state is still intercepting")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("state was not persisted")
+	}
+
+	h.Release(state.ScopePool, "gpu", "alice", "", 0)
+	select {
+	case st := <-store.saved:
+		if len(st.Releases) != 1 || st.Releases[0].Name != "gpu" {
+			t.Errorf("persisted releases = %+v, want pool gpu", st.Releases)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("state was not persisted")
+	}
+}
+
+func TestApplyStateIgnoresStaleState(t *testing.T) {
+	h := NewCallbackHandler()
+	h.Arm(ArmedByCallback, "operator")
+
+	h.applyState(&state.State{Intercepting: false, UpdatedAt: time.Now().Add(-time.Hour)})
+	if !h.IsIntercepting() {
+		t.Error("stale state overrode a newer local change")
+	}
+
+	h.applyState(&state.State{
+		Intercepting: false,
+		Releases:     []state.Release{{Scope: state.ScopeNode, Name: "node-1", ReleasedBy: "alice"}},
+		UpdatedAt:    time.Now().Add(time.Second),
+	})
+	if h.IsIntercepting() {
+		t.Error("newer state was not applied")
+	}
+	if _, released := h.Released(state.ScopeNode, "node-1"); !released {
+		t.Error("releases of the newer state were not applied")
+	}
+}
+
+func TestTimeBoxedDisableAndRelease(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	fakeClock := clocktesting.NewFakePassiveClock(now)
+	h := NewCallbackHandler()
+	h.SetClock(fakeClock)
+	h.Arm(ArmedByAuto, "storm")
+
+	rec := doBody(t, h, http.MethodPost, "/callback/disable-interception", `{"ttl":"30m","reason":"INC-42"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("disable: status code = %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = doBody(t, h, http.MethodPost, "/callback/release/node/node-1", `{"ttl":"10m","reason":"node is gone"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("release: status code = %d: %s", rec.Code, rec.Body.String())
+	}
+	h.Release(state.ScopeNamespace, "batch", "alice", "", 0)
+	for _, body := range []string{`{"ttl":"-5m"}`, `{"ttl":"soon"}`} {
+		if rec := doBody(t, h, http.MethodPost, "/callback/release/node/node-2", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("release with %s: status code = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	fakeClock.SetTime(now.Add(5 * time.Minute))
+	status := getStatus(t, h)
+	if status.Data.Intercepting || status.Data.Disabled == nil {
+		t.Fatalf("intercepting = %v, disabled = %+v, want a disable", status.Data.Intercepting, status.Data.Disabled)
+	}
+	if got := status.Data.Disabled; got.DisabledReason != "INC-42" || got.Remaining != "25m0s" || !got.ExpiresAt.Equal(now.Add(30*time.Minute)) {
+		t.Errorf("disabled = %+v, want reason INC-42 expiring in 25m0s", got)
+	}
+	if len(status.Data.Releases) != 2 || status.Data.Releases[1].Remaining != "5m0s" || status.Data.Releases[1].Reason != "node is gone" {
+		t.Errorf("releases = %+v, want node-1 expiring in 5m0s", status.Data.Releases)
+	}
+
+	// The node release expires first, decisions ignore it before Expire runs
+	fakeClock.SetTime(now.Add(10 * time.Minute))
+	if _, released := h.Released(state.ScopeNode, "node-1"); released {
+		t.Error("node-1 is still released after its TTL")
+	}
+	expired := h.Expire()
+	if len(expired) != 1 || expired[0].Name != "node-1" {
+		t.Errorf("expired = %+v, want node-1", expired)
+	}
+
+	fakeClock.SetTime(now.Add(30 * time.Minute))
+	if !h.IsIntercepting() {
+		t.Error("interception is still disabled after the TTL")
+	}
+	expired = h.Expire()
+	if len(expired) != 1 || expired[0].Scope != ScopeGlobal || expired[0].ReleasedBy == "" {
+		t.Errorf("expired = %+v, want the global disable", expired)
+	}
+	if h.ArmedBy() != ArmedByExpiry {
+		t.Errorf("armedBy = %q, want %q", h.ArmedBy(), ArmedByExpiry)
+	}
+	// State changes are stamped with the handler's clock, which drives last-writer-wins
+	if snapshot := h.snapshot(); !snapshot.ArmedAt.Equal(now.Add(30*time.Minute)) || !snapshot.UpdatedAt.Equal(now.Add(30*time.Minute)) {
+		t.Errorf("armedAt = %v, updatedAt = %v, want the fake clock time", snapshot.ArmedAt, snapshot.UpdatedAt)
+	}
+	if expired := h.Expire(); len(expired) != 0 {
+		t.Errorf("expired again: %+v", expired)
+	}
+	if _, released := h.Released(state.ScopeNamespace, "batch"); !released {
+		t.Error("a release without TTL expired")
+	}
+
+	// Enabling interception drops the disable record
+	doBody(t, h, http.MethodPost, "/callback/disable-interception", `{"ttl":"1h"}`)
+	do(t, h, http.MethodPost, "/callback/enable-interception")
+	if status := getStatus(t, h); status.Data.Disabled != nil {
+		t.Errorf("disabled = %+v after enable", status.Data.Disabled)
+	}
+}
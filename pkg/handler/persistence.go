@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kbsonlong/webhook/pkg/state"
+	"k8s.io/klog/v2"
+)
+
+// persistRetryInterval 持久化失败后的重试间隔
+const persistRetryInterval = 5 * time.Second
+
+// SetStore 设置拦截状态的持久化存储
+func (h *CallbackHandler) SetStore(store state.Store) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.store = store
+}
+
+// Restore 启动时从存储中恢复拦截状态
+// NotReady 节点列表由 NodeMonitor 根据节点 informer 重新构建，不从存储中恢复
+func (h *CallbackHandler) Restore(ctx context.Context) error {
+	if h.store == nil {
+		return nil
+	}
+
+	st, err := h.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to restore interception state: %w", err)
+	}
+	if st == nil {
+		klog.Infof("No persisted interception state found")
+		return nil
+	}
+
+	h.applyState(st)
+	klog.Infof("Restored interception state: intercepting=%v, armedBy=%s, armedReason=%q, releases: %d, persisted NotReady nodes: %v",
+		st.Intercepting, st.ArmedBy, st.ArmedReason, len(st.Releases), st.NotReadyNodes)
+	return nil
+}
+
+// Run 持久化本地状态变化并同步其他副本写入的状态，直到 ctx 结束
+func (h *CallbackHandler) Run(ctx context.Context) {
+	if h.store == nil {
+		return
+	}
+
+	go h.store.Watch(ctx, h.applyState)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.dirty:
+			if err := h.store.Save(ctx, h.snapshot()); err != nil {
+				klog.Errorf("Failed to persist interception state, retrying in %v: %v", persistRetryInterval, err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(persistRetryInterval):
+				}
+				h.markDirty()
+			}
+		}
+	}
+}
+
+// snapshot 返回当前状态的副本
+func (h *CallbackHandler) snapshot() *state.State {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return &state.State{
+		Intercepting:   h.intercepting,
+		ArmedAt:        h.armedAt,
+		ArmedBy:        h.armedBy,
+		ArmedReason:    h.armedReason,
+		DisabledAt:     h.disabled.at,
+		DisabledBy:     h.disabled.by,
+		DisabledReason: h.disabled.reason,
+		DisabledUntil:  h.disabled.until,
+		NotReadyNodes:  h.getNotReadyNodeNames(),
+		Releases:       h.getReleases(),
+		UpdatedAt:      h.updatedAt,
+	}
+}
+
+// applyState 应用存储中比本地更新的拦截状态
+func (h *CallbackHandler) applyState(st *state.State) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !st.UpdatedAt.After(h.updatedAt) {
+		return
+	}
+	if h.intercepting != st.Intercepting {
+		klog.Infof("Applying interception state from store: intercepting=%v, armedBy=%s", st.Intercepting, st.ArmedBy)
+	}
+	h.intercepting = st.Intercepting
+	h.armedAt = st.ArmedAt
+	h.armedBy = st.ArmedBy
+	h.armedReason = st.ArmedReason
+	h.disabled = disableRecord{at: st.DisabledAt, by: st.DisabledBy, reason: st.DisabledReason, until: st.DisabledUntil}
+	h.setReleases(st.Releases)
+	h.updatedAt = st.UpdatedAt
+	if h.intercepting {
+		interceptionArmed.Set(1)
+	} else {
+		interceptionArmed.Set(0)
+	}
+}
+
+// markDirty 通知 Run 持久化状态
+func (h *CallbackHandler) markDirty() {
+	select {
+	case h.dirty <- struct{}{}:
+	default:
+	}
+}
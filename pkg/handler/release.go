@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kbsonlong/webhook/pkg/state"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+var (
+	interceptionReleases = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "interception_releases",
+		Help: "Number of nodes, node pools and namespaces released from interception",
+	}, []string{"scope"})
+	interceptionReleaseExpired = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "interception_release_expired_total",
+		Help: "Number of time-boxed disables (scope global) and releases that expired and were protected again",
+	}, []string{"scope"})
+)
+
+// ScopeGlobal 全局禁用拦截的范围，用于到期记录和指标
+const ScopeGlobal = "global"
+
+// releaseScopes 支持单独解除拦截的范围
+var releaseScopes = []string{state.ScopeNode, state.ScopePool, state.ScopeNamespace}
+
+// releaseRequest 禁用或解除拦截请求的可选参数
+type releaseRequest struct {
+	TTL    metav1.Duration `json:"ttl"`    // 有效期，如 30m，为空时一直有效
+	Reason string          `json:"reason"` // 原因，记录在状态和事件中
+}
+
+// bindReleaseRequest 解析可选的请求体，无效时返回 400
+func bindReleaseRequest(c *gin.Context) (releaseRequest, bool) {
+	var req releaseRequest
+	if c.Request.Body != nil && c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": fmt.Sprintf("invalid request body: %v", err),
+			})
+			return req, false
+		}
+	}
+	if req.TTL.Duration < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": fmt.Sprintf("invalid ttl %v, must not be negative", req.TTL.Duration),
+		})
+		return req, false
+	}
+	return req, true
+}
+
+// ttlName 返回有效期的可读名称
+func ttlName(ttl time.Duration) string {
+	if ttl == 0 {
+		return "no time limit"
+	}
+	return ttl.String()
+}
+
+// remaining 返回距离到期的剩余时间，精确到秒
+func remaining(until, now time.Time) string {
+	left := until.Sub(now).Round(time.Second)
+	if left < 0 {
+		left = 0
+	}
+	return left.String()
+}
+
+// releaseStatus 状态接口中的解除拦截记录
+type releaseStatus struct {
+	state.Release
+	Remaining string `json:"remaining,omitempty"` // 距离到期的剩余时间
+}
+
+// releaseKey 解除拦截记录的键
+type releaseKey struct {
+	scope string
+	name  string
+}
+
+// ReleaseScope 解除指定节点、节点池或命名空间的拦截，其他范围仍然受保护
+func (h *CallbackHandler) ReleaseScope(c *gin.Context) {
+	scope, name, ok := scopeParams(c)
+	if !ok {
+		return
+	}
+	req, ok := bindReleaseRequest(c)
+	if !ok {
+		return
+	}
+
+	by := caller(c)
+	release := h.Release(scope, name, by, req.Reason, req.TTL.Duration)
+	klog.Infof("Interception released for %s %s via callback by %s for %s: %s",
+		scope, name, by, ttlName(req.TTL.Duration), req.Reason)
+	message := fmt.Sprintf("%s %s released successfully", scope, name)
+	if !release.ExpiresAt.IsZero() {
+		message += " until " + release.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message,
+	})
+}
+
+// ProtectScope 恢复之前解除拦截的节点、节点池或命名空间的保护
+func (h *CallbackHandler) ProtectScope(c *gin.Context) {
+	scope, name, ok := scopeParams(c)
+	if !ok {
+		return
+	}
+
+	if !h.Protect(scope, name) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": fmt.Sprintf("%s %s is not released", scope, name),
+		})
+		return
+	}
+	klog.Infof("Interception restored for %s %s via callback by %s", scope, name, caller(c))
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": fmt.Sprintf("%s %s protected successfully", scope, name),
+	})
+}
+
+// scopeParams 解析路径中的范围和名称，无效时返回 400
+func scopeParams(c *gin.Context) (string, string, bool) {
+	scope, name := c.Param("scope"), c.Param("name")
+	for _, valid := range releaseScopes {
+		if scope == valid && name != "" {
+			return scope, name, true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"status":  "error",
+		"message": fmt.Sprintf("invalid scope %q, must be one of %v", scope, releaseScopes),
+	})
+	return "", "", false
+}
+
+// Release 解除指定范围的拦截，ttl 为 0 时一直有效。已经解除的范围会被新的记录替换，
+// 可以用来延长或取消有效期
+func (h *CallbackHandler) Release(scope, name, by, reason string, ttl time.Duration) state.Release {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	release := state.Release{
+		Scope:      scope,
+		Name:       name,
+		ReleasedAt: h.clock.Now(),
+		ReleasedBy: by,
+		Reason:     reason,
+	}
+	if ttl > 0 {
+		release.ExpiresAt = release.ReleasedAt.Add(ttl)
+	}
+	h.releases[releaseKey{scope: scope, name: name}] = release
+	h.releasesChanged()
+	return release
+}
+
+// Protect 恢复指定范围的拦截，返回状态是否发生变化
+func (h *CallbackHandler) Protect(scope, name string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := releaseKey{scope: scope, name: name}
+	if _, exists := h.releases[key]; !exists {
+		return false
+	}
+	delete(h.releases, key)
+	h.releasesChanged()
+	return true
+}
+
+// Released 返回指定范围的解除拦截记录，未解除或已到期时返回 false
+func (h *CallbackHandler) Released(scope, name string) (state.Release, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	release, exists := h.releases[releaseKey{scope: scope, name: name}]
+	if !exists || release.Expired(h.clock.Now()) {
+		return state.Release{}, false
+	}
+	return release, true
+}
+
+// Expire 重新启用到期的限时禁用，并恢复到期解除的范围的拦截，返回到期的记录，
+// 全局禁用的范围为 ScopeGlobal
+func (h *CallbackHandler) Expire() []state.Release {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.clock.Now()
+	var expired []state.Release
+	if h.disableExpired(now) {
+		disabled := h.disabled
+		expired = append(expired, state.Release{
+			Scope:      ScopeGlobal,
+			ReleasedAt: disabled.at,
+			ReleasedBy: disabled.by,
+			Reason:     disabled.reason,
+			ExpiresAt:  disabled.until,
+		})
+		h.arm(ArmedByExpiry, fmt.Sprintf("interception disabled by %s at %s expired",
+			disabled.by, disabled.at.UTC().Format(time.RFC3339)))
+	}
+
+	releasesExpired := false
+	for key, release := range h.releases {
+		if release.Expired(now) {
+			delete(h.releases, key)
+			expired = append(expired, release)
+			releasesExpired = true
+		}
+	}
+	if releasesExpired {
+		h.releasesChanged()
+	}
+
+	sort.Slice(expired, func(i, j int) bool {
+		if expired[i].Scope != expired[j].Scope {
+			return expired[i].Scope < expired[j].Scope
+		}
+		return expired[i].Name < expired[j].Name
+	})
+	for _, release := range expired {
+		interceptionReleaseExpired.WithLabelValues(release.Scope).Inc()
+	}
+	return expired
+}
+
+// Releases 返回所有解除拦截的范围，按范围和名称排序
+func (h *CallbackHandler) Releases() []state.Release {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.getReleases()
+}
+
+// getReleases 返回排序后的解除拦截记录，调用方需持有锁
+func (h *CallbackHandler) getReleases() []state.Release {
+	releases := make([]state.Release, 0, len(h.releases))
+	for _, release := range h.releases {
+		releases = append(releases, release)
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].Scope != releases[j].Scope {
+			return releases[i].Scope < releases[j].Scope
+		}
+		return releases[i].Name < releases[j].Name
+	})
+	return releases
+}
+
+// releaseStatuses 返回未到期的解除拦截记录及剩余时间，调用方需持有锁
+func (h *CallbackHandler) releaseStatuses(now time.Time) []releaseStatus {
+	statuses := make([]releaseStatus, 0, len(h.releases))
+	for _, release := range h.getReleases() {
+		if release.Expired(now) {
+			continue
+		}
+		status := releaseStatus{Release: release}
+		if !release.ExpiresAt.IsZero() {
+			status.Remaining = remaining(release.ExpiresAt, now)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// setReleases 替换所有解除拦截记录，调用方需持有锁
+func (h *CallbackHandler) setReleases(releases []state.Release) {
+	h.releases = make(map[releaseKey]state.Release, len(releases))
+	for _, release := range releases {
+		h.releases[releaseKey{scope: release.Scope, name: release.Name}] = release
+	}
+	h.updateReleaseMetrics()
+}
+
+// releasesChanged 记录解除拦截记录的变化并通知持久化，调用方需持有锁
+func (h *CallbackHandler) releasesChanged() {
+	h.updatedAt = h.clock.Now()
+	h.updateReleaseMetrics()
+	h.markDirty()
+}
+
+// updateReleaseMetrics 更新各范围解除拦截数量的指标，调用方需持有锁
+func (h *CallbackHandler) updateReleaseMetrics() {
+	counts := make(map[string]int, len(releaseScopes))
+	for key := range h.releases {
+		counts[key.scope]++
+	}
+	for _, scope := range releaseScopes {
+		interceptionReleases.WithLabelValues(scope).Set(float64(counts[scope]))
+	}
+}
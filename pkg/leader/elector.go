@@ -0,0 +1,89 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// Elector decides which replica performs cluster-wide side effects such as arming
+// interception and emitting state change events. A nil Elector always reports
+// leadership, which is used when leader election is disabled.
+type Elector struct {
+	identity string
+	isLeader atomic.Bool
+	config   leaderelection.LeaderElectionConfig
+}
+
+// NewElector creates a new Elector backed by a Lease in the given namespace
+func NewElector(clientset kubernetes.Interface, namespace, leaseName, identity string) (*Elector, error) {
+	lock, err := resourcelock.New(
+		resourcelock.LeasesResourceLock,
+		namespace,
+		leaseName,
+		clientset.CoreV1(),
+		clientset.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: identity},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create leader election lock: %w", err)
+	}
+
+	e := &Elector{identity: identity}
+	e.config = leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Name:            leaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.Infof("Replica %s started leading", identity)
+				e.isLeader.Store(true)
+			},
+			OnStoppedLeading: func() {
+				klog.Infof("Replica %s stopped leading", identity)
+				e.isLeader.Store(false)
+			},
+			OnNewLeader: func(current string) {
+				if current != identity {
+					klog.Infof("Replica %s is the current leader", current)
+				}
+			},
+		},
+	}
+	return e, nil
+}
+
+// Run participates in leader election until ctx is done, rejoining the election
+// whenever leadership is lost
+func (e *Elector) Run(ctx context.Context) {
+	for {
+		leaderelection.RunOrDie(ctx, e.config)
+		if ctx.Err() != nil {
+			return
+		}
+		klog.Warningf("Replica %s lost leadership, rejoining election", e.identity)
+	}
+}
+
+// IsLeader reports whether this replica should perform cluster-wide side effects
+func (e *Elector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	return e.isLeader.Load()
+}
@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// ModeAnnotation lets teams override the pool default on their pods and namespaces,
+// a pod annotation takes precedence over a namespace annotation
+const ModeAnnotation = "eviction-protection.io/mode"
+
+// Values of the mode annotation
+const (
+	// ModeProtect intercepts evictions while the pod's node is NotReady and interception
+	// is enabled, even when the pool is below its threshold
+	ModeProtect = "protect"
+	// ModeIgnore never intercepts evictions of the pod
+	ModeIgnore = "ignore"
+	// ModeAudit allows evictions that would be intercepted and reports them instead,
+	// like a pool in audit mode
+	ModeAudit = "audit"
+)
+
+// Levels that decide an eviction, from the most to the least specific
+const (
+	LevelPod       = "pod"
+	LevelNamespace = "namespace"
+	LevelPool      = "pool"
+)
+
+// resolveMode returns the mode annotation governing a pod and the level it was set on,
+// or an empty mode when the pool default applies. Invalid values are ignored.
+func (m *NodeMonitor) resolveMode(pod *v1.Pod) (string, string) {
+	if mode, ok := modeAnnotation(pod.Annotations, "pod "+pod.Namespace+"/"+pod.Name); ok {
+		return mode, LevelPod
+	}
+
+	m.mu.RLock()
+	namespaces := m.namespaces
+	m.mu.RUnlock()
+	if namespaces == nil || pod.Namespace == "" {
+		return "", LevelPool
+	}
+	namespace, err := namespaces.Get(pod.Namespace)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Errorf("Failed to get namespace %s: %v", pod.Namespace, err)
+		}
+		return "", LevelPool
+	}
+	if mode, ok := modeAnnotation(namespace.Annotations, "namespace "+namespace.Name); ok {
+		return mode, LevelNamespace
+	}
+	return "", LevelPool
+}
+
+// modeAnnotation returns a valid mode annotation value, object names the annotated object
+func modeAnnotation(annotations map[string]string, object string) (string, bool) {
+	mode, exists := annotations[ModeAnnotation]
+	if !exists {
+		return "", false
+	}
+	switch mode {
+	case ModeProtect, ModeIgnore, ModeAudit:
+		return mode, true
+	default:
+		klog.Warningf("Ignoring invalid %s annotation %q on %s", ModeAnnotation, mode, object)
+		return "", false
+	}
+}
+
+// applyMode overrides the pool decision with the mode annotation of the pod or its namespace
+func (m *NodeMonitor) applyMode(explanation *Explanation, pod *v1.Pod) {
+	mode, level := m.resolveMode(pod)
+	if mode == "" {
+		return
+	}
+	source := fmt.Sprintf("%s annotation %s=%s", level, ModeAnnotation, mode)
+
+	switch mode {
+	case ModeIgnore:
+		explanation.Level = level
+		explanation.Intercept = false
+		explanation.Audit = false
+		explanation.Code = ReasonModeIgnore
+		explanation.Reason = fmt.Sprintf("evictions are not intercepted by %s", source)
+	case ModeProtect:
+		// Protection still requires interception to be enabled and no release of the node
+		// or pool, so the administrator can free protected pods as well
+		if explanation.NodeNotReady && !releasedByAdministrator(explanation.Code) {
+			explanation.Level = level
+			explanation.Intercept = true
+			explanation.Audit = false
+			explanation.Code = ReasonModeProtect
+			explanation.Reason = fmt.Sprintf("node is NotReady and the pod is protected by %s", source)
+		}
+	case ModeAudit:
+		if explanation.Intercept {
+			explanation.Level = level
+			explanation.Intercept = false
+			explanation.Audit = true
+			explanation.Reason = fmt.Sprintf("audited by %s: %s", source, explanation.Reason)
+		}
+	}
+}
+
+// trimNamespace keeps the metadata of a namespace, the only part read by decisions
+func trimNamespace(obj interface{}) (interface{}, error) {
+	namespace, ok := obj.(*v1.Namespace)
+	if !ok {
+		return obj, nil
+	}
+	namespace.ManagedFields = nil
+	namespace.Spec = v1.NamespaceSpec{}
+	namespace.Status = v1.NamespaceStatus{}
+	return namespace, nil
+}
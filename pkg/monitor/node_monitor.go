@@ -3,20 +3,29 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kbsonlong/webhook/pkg/config"
+	"github.com/kbsonlong/webhook/pkg/events"
 	"github.com/kbsonlong/webhook/pkg/handler"
+	"github.com/kbsonlong/webhook/pkg/leader"
+	"github.com/kbsonlong/webhook/pkg/state"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
 var (
@@ -24,36 +33,95 @@ var (
 		Name: "node_notready_count",
 		Help: "Number of nodes in NotReady state",
 	})
+	nodePoolNotReadyCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "node_pool_notready_count",
+		Help: "Number of nodes in NotReady state per node pool",
+	}, []string{"pool"})
+	nodePoolNodeCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "node_pool_node_count",
+		Help: "Number of nodes matching each node pool",
+	}, []string{"pool"})
+	nodePoolNotReadyWindowCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "node_pool_notready_window_count",
+		Help: "Number of nodes per node pool that became NotReady within the pool window",
+	}, []string{"pool"})
+	nodePoolThreshold = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "node_pool_threshold",
+		Help: "Effective NotReady threshold per node pool",
+	}, []string{"pool"})
+	nodePoolArmed = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "node_pool_armed",
+		Help: "Whether evictions on the node pool are currently intercepted (1) or not (0)",
+	}, []string{"pool"})
+	nodeNotReadyDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "node_notready_duration_seconds",
+		Help:    "How long nodes stayed NotReady before becoming Ready or being deleted",
+		Buckets: []float64{10, 30, 60, 120, 300, 600, 1800, 3600, 7200, 21600, 86400},
+	}, []string{"pool"})
 )
 
+// evaluationInterval is how often pools are re-evaluated for window expiry and auto-release
+const evaluationInterval = 30 * time.Second
+
 // NodeMonitor monitors the state of nodes in the cluster
 type NodeMonitor struct {
-	clientset     *kubernetes.Clientset
-	notReadyNodes map[string]time.Time
+	clientset     kubernetes.Interface
+	nodes         map[string]*nodeState   // every known node
+	pools         []*poolState            // node pools in matching order, followed by the default pool
+	poolsByName   map[string]*poolState   // node pools by name, including the default pool
+	filePools     []config.NodePoolConfig // node pools from the config file, swapped on reload
+	policyPools   []config.NodePoolConfig // node pools from EvictionProtectionPolicy resources
+	notReadyCount int                     // number of NotReady nodes across all pools
 	mu            sync.RWMutex
 	config        *config.Config
 	callback      *handler.CallbackHandler
+	recorder      *events.Recorder
+	elector       *leader.Elector
+	overThreshold bool                        // whether any pool was over its threshold at the last evaluation
+	armEvaluated  bool                        // whether this replica evaluated arming as leader since the pools crossed their thresholds
+	metricPools   map[string]struct{}         // pools with per-pool metric series, to delete the series of removed pools
+	belowSince    time.Time                   // when all pools dropped below their thresholds
+	namespaces    corelisters.NamespaceLister // namespace annotations, nil until started
+	synced        atomic.Bool
+	clock         clock.PassiveClock
 }
 
 // NewNodeMonitor creates a new NodeMonitor instance
-func NewNodeMonitor(clientset *kubernetes.Clientset, cfg *config.Config, callback *handler.CallbackHandler) *NodeMonitor {
-	return &NodeMonitor{
-		clientset:     clientset,
-		notReadyNodes: make(map[string]time.Time),
-		config:        cfg,
-		callback:      callback,
+// elector may be nil when leader election is disabled.
+func NewNodeMonitor(clientset kubernetes.Interface, cfg *config.Config, callback *handler.CallbackHandler,
+	recorder *events.Recorder, elector *leader.Elector) *NodeMonitor {
+	m := &NodeMonitor{
+		clientset: clientset,
+		nodes:     make(map[string]*nodeState),
+		filePools: cfg.NodePools,
+		config:    cfg,
+		callback:  callback,
+		recorder:  recorder,
+		elector:   elector,
+		clock:     clock.RealClock{},
 	}
+	m.rebuildPools()
+	callback.SetPoolStatusFunc(m.PoolStatuses)
+	return m
+}
+
+// SetClock replaces the clock used to evaluate pool windows, intended for tests
+// and offline evaluation. Must be called before nodes are observed.
+func (m *NodeMonitor) SetClock(clock clock.PassiveClock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = clock
 }
 
 // Start begins monitoring nodes
 func (m *NodeMonitor) Start(ctx context.Context) error {
-	// Create a node informer
-	nodeInformer := cache.NewSharedIndexInformer(
-		cache.NewListWatchFromClient(m.clientset.CoreV1().RESTClient(), "nodes", "", fields.Everything()),
-		&v1.Node{},
-		0,
-		cache.Indexers{},
-	)
+	// Create a node informer, and a namespace informer for the mode annotations
+	factory := informers.NewSharedInformerFactory(m.clientset, 0)
+	nodeInformer := factory.Core().V1().Nodes().Informer()
+	namespaceInformer := factory.Core().V1().Namespaces()
+	if err := namespaceInformer.Informer().SetTransform(trimNamespace); err != nil {
+		return fmt.Errorf("failed to set namespace transform: %w", err)
+	}
 
 	// Add event handlers
 	nodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -63,104 +131,414 @@ func (m *NodeMonitor) Start(ctx context.Context) error {
 	})
 
 	// Start the informer
-	go nodeInformer.Run(ctx.Done())
+	factory.Start(ctx.Done())
 
 	// Wait for the cache to sync
-	if !cache.WaitForCacheSync(ctx.Done(), nodeInformer.HasSynced) {
-		return fmt.Errorf("failed to sync node cache")
+	if !cache.WaitForCacheSync(ctx.Done(), nodeInformer.HasSynced, namespaceInformer.Informer().HasSynced) {
+		return fmt.Errorf("failed to sync node and namespace caches")
 	}
+	m.mu.Lock()
+	m.namespaces = namespaceInformer.Lister()
+	m.mu.Unlock()
+	m.markSynced()
+
+	// Periodically re-evaluate pools and expire releases, NotReady nodes leave the window
+	// and releases run out without informer events
+	go wait.Until(func() {
+		m.expireReleases()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.evaluatePools()
+		m.updateMetrics()
+	}, evaluationInterval, ctx.Done())
 
 	return nil
 }
 
-// ShouldInterceptEviction checks if eviction should be intercepted
-func (m *NodeMonitor) ShouldInterceptEviction(pod *v1.Pod) bool {
-	// If the callback is not intercepting, allow eviction
-	if !m.callback.IsIntercepting() {
-		klog.Infof("Interception is disabled via callback, allowing eviction for pod %s/%s",
-			pod.Namespace, pod.Name)
-		return false
+// Reason codes of eviction decisions, used as metric labels
+const (
+	ReasonInterceptionDisabled    = "InterceptionDisabled"
+	ReasonNoNode                  = "NoNodeAssigned"
+	ReasonNodeUnknown             = "NodeUnknown"
+	ReasonNodeReady               = "NodeReady"
+	ReasonBelowThreshold          = "BelowThreshold"
+	ReasonThresholdReached        = "ThresholdReached"
+	ReasonUpdateNotIntercepted    = "UpdateNotIntercepted"
+	ReasonRequesterAllowed        = "RequesterAllowed"
+	ReasonRequesterNotIntercepted = "RequesterNotIntercepted"
+	ReasonModeIgnore              = "ModeIgnore"
+	ReasonModeProtect             = "ModeProtect"
+	ReasonNodeReleased            = "NodeReleased"
+	ReasonPoolReleased            = "PoolReleased"
+	ReasonNamespaceReleased       = "NamespaceReleased"
+)
+
+// HasSynced reports whether the node cache has synced, decisions before that are blind
+func (m *NodeMonitor) HasSynced() bool {
+	return m.synced.Load()
+}
+
+// markSynced records that the initial node list is complete and evaluates the pools,
+// which could not arm interception while their membership was partial
+func (m *NodeMonitor) markSynced() {
+	m.synced.Store(true)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evaluatePools()
+	m.updateMetrics()
+}
+
+// Explanation describes how an eviction decision was reached
+type Explanation struct {
+	Pool          string // node pool of the pod's node
+	NodeNotReady  bool   // whether the pod's node is NotReady
+	NotReadyCount int    // NotReady nodes of the pool within the window
+	Threshold     int    // effective threshold of the pool
+	Intercept     bool   // whether the eviction is intercepted
+	Audit         bool   // whether the eviction would be intercepted for Code but is only audited
+	Level         string // level that decided: pod, namespace, node or pool
+	Code          string // machine readable reason of the decision
+	Reason        string // human readable reason of the decision
+}
+
+// ShouldInterceptEviction checks if eviction should be intercepted. The decision only
+// reads the precomputed node and pool state, it never scans nodes or calls the apiserver.
+// user is the requester of the eviction, matched against the pool's requester lists.
+func (m *NodeMonitor) ShouldInterceptEviction(pod *v1.Pod, user authenticationv1.UserInfo) Explanation {
+	explanation := m.explain(pod.Spec.NodeName)
+	m.applyMode(&explanation, pod)
+	m.applyNamespaceRelease(&explanation, pod)
+	m.filterRequester(&explanation, user)
+	klog.Infof("Should intercept eviction for pod %s/%s on node %s by %s: %v (%s)",
+		pod.Namespace, pod.Name, pod.Spec.NodeName, user.Username, explanation.Intercept, explanation.Reason)
+	return explanation
+}
+
+// ShouldInterceptUpdate checks if a pod update of the given class should be intercepted.
+// class is empty for updates unrelated to eviction, such as label or finalizer changes,
+// which are never intercepted. Otherwise the pool of the pod's node decides.
+func (m *NodeMonitor) ShouldInterceptUpdate(pod *v1.Pod, class string, user authenticationv1.UserInfo) Explanation {
+	explanation := m.explain(pod.Spec.NodeName)
+	m.applyMode(&explanation, pod)
+	m.applyNamespaceRelease(&explanation, pod)
+	if explanation.Intercept || explanation.Audit {
+		m.mu.RLock()
+		pool := m.poolsByName[explanation.Pool]
+		intercepted := class != "" && pool != nil && pool.interceptUpdates[class]
+		m.mu.RUnlock()
+		if !intercepted {
+			explanation.Intercept = false
+			explanation.Audit = false
+			explanation.Code = ReasonUpdateNotIntercepted
+			if class == "" {
+				explanation.Reason = "pod update is not related to eviction"
+			} else {
+				explanation.Reason = fmt.Sprintf("%s updates are not intercepted on node pool %s", class, explanation.Pool)
+			}
+		}
+	}
+	m.filterRequester(&explanation, user)
+	klog.Infof("Should intercept %s update of pod %s/%s on node %s by %s: %v (%s)",
+		updateClassName(class), pod.Namespace, pod.Name, pod.Spec.NodeName, user.Username, explanation.Intercept, explanation.Reason)
+	return explanation
+}
+
+// updateClassName returns a printable name of a pod update class
+func updateClassName(class string) string {
+	if class == "" {
+		return "unrelated"
+	}
+	return class
+}
+
+// ExplainEviction evaluates the eviction of a pod running on the given node,
+// node may be nil when the pod is not scheduled
+func (m *NodeMonitor) ExplainEviction(pod *v1.Pod, node *v1.Node) Explanation {
+	if node == nil {
+		return Explanation{Code: ReasonNoNode, Reason: "pod has no node assigned", Level: LevelPool}
+	}
+	explanation := m.explain(node.Name)
+	m.applyMode(&explanation, pod)
+	m.applyNamespaceRelease(&explanation, pod)
+	return explanation
+}
+
+// explain evaluates the eviction of a pod running on the named node
+func (m *NodeMonitor) explain(nodeName string) Explanation {
+	if nodeName == "" {
+		return Explanation{Code: ReasonNoNode, Reason: "pod has no node assigned", Level: LevelPool}
 	}
 
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	// If the pod is not on a NotReady node, allow eviction
-	if pod.Spec.NodeName == "" {
-		klog.Infof("Pod %s/%s has no node assigned, allowing eviction", pod.Namespace, pod.Name)
-		return false
+	node, exists := m.nodes[nodeName]
+	if !exists {
+		return Explanation{Code: ReasonNodeUnknown, Reason: fmt.Sprintf("node %s is not known", nodeName), Level: LevelPool}
+	}
+	pool := node.pool
+	explanation := Explanation{
+		Pool:          pool.config.Name,
+		NodeNotReady:  !node.notReadySince.IsZero(),
+		NotReadyCount: pool.notReadyWithinWindow(m.clock.Now()),
+		Threshold:     m.resolveThreshold(pool),
+		Level:         LevelPool,
 	}
-	klog.Infof("Checking pod %s/%s on node: %s", pod.Namespace, pod.Name, pod.Spec.NodeName)
 
-	// Check if the node is in our NotReady list
-	timestamp, exists := m.notReadyNodes[pod.Spec.NodeName]
-	if !exists {
-		klog.Infof("Node %s is Ready, allowing eviction for pod %s/%s",
-			pod.Spec.NodeName, pod.Namespace, pod.Name)
-		return false
+	switch {
+	case !m.callback.IsIntercepting():
+		explanation.Code = ReasonInterceptionDisabled
+		explanation.Reason = "interception is disabled"
+	case !explanation.NodeNotReady:
+		explanation.Code = ReasonNodeReady
+		explanation.Reason = fmt.Sprintf("node %s is Ready", nodeName)
+	case m.explainRelease(&explanation, state.ScopeNode, nodeName, LevelNode):
+	case m.explainNodeAnnotation(&explanation, nodeName, node):
+	case m.explainRelease(&explanation, state.ScopePool, pool.config.Name, LevelPool):
+	case explanation.NotReadyCount < explanation.Threshold:
+		explanation.Code = ReasonBelowThreshold
+		explanation.Reason = fmt.Sprintf("node pool %s has %d NotReady nodes within %v, below threshold %d",
+			pool.config.Name, explanation.NotReadyCount, pool.config.Window.Duration, explanation.Threshold)
+	default:
+		explanation.Code = ReasonThresholdReached
+		explanation.Reason = fmt.Sprintf("node pool %s has %d NotReady nodes within %v, reaching threshold %d",
+			pool.config.Name, explanation.NotReadyCount, pool.config.Window.Duration, explanation.Threshold)
+		// Pools in audit mode report the eviction with the reason they would deny it for
+		if pool.audit() {
+			explanation.Audit = true
+		} else {
+			explanation.Intercept = true
+		}
 	}
-	klog.Infof("Node %s is in NotReady list since %v", pod.Spec.NodeName, timestamp)
+	return explanation
+}
 
-	// Get node information
-	node, err := m.clientset.CoreV1().Nodes().Get(context.Background(), pod.Spec.NodeName, metav1.GetOptions{})
-	if err != nil {
-		klog.Errorf("Failed to get node %s: %v", pod.Spec.NodeName, err)
-		return false
+// ObserveNode records the state of a node as if it was received from the node informer
+func (m *NodeMonitor) ObserveNode(node *v1.Node) {
+	m.updateNodeStatus(node)
+}
+
+// PoolStatuses returns the current NotReady bookkeeping of every node pool
+func (m *NodeMonitor) PoolStatuses() map[string]handler.PoolStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.clock.Now()
+	intercepting := m.callback.IsIntercepting()
+	statuses := make(map[string]handler.PoolStatus, len(m.pools))
+	for _, pool := range m.pools {
+		status := handler.PoolStatus{
+			NotReadyNodes: make([]string, 0, len(pool.notReadyNodes)),
+			NotReadyCount: pool.notReadyWithinWindow(now),
+			TotalNodes:    pool.nodes,
+			Threshold:     m.resolveThreshold(pool),
+			Mode:          config.PoolModeEnforce,
+		}
+		status.Armed = m.poolArmed(pool, intercepting, status.NotReadyCount, status.Threshold)
+		if pool.audit() {
+			status.Mode = config.PoolModeAudit
+		}
+		for nodeName := range pool.notReadyNodes {
+			status.NotReadyNodes = append(status.NotReadyNodes, nodeName)
+		}
+		sort.Strings(status.NotReadyNodes)
+		statuses[pool.config.Name] = status
 	}
+	for nodeName, node := range m.nodes {
+		if node.release.active(now) {
+			status := statuses[node.pool.config.Name]
+			status.ReleasedNodes = append(status.ReleasedNodes, nodeName)
+			statuses[node.pool.config.Name] = status
+		}
+	}
+	for name, status := range statuses {
+		sort.Strings(status.ReleasedNodes)
+		statuses[name] = status
+	}
+	return statuses
+}
 
-	// Find matching node pool configuration
-	poolConfig := m.findMatchingNodePool(node)
-	if poolConfig == nil {
-		// Use default configuration
-		poolConfig = &config.NodePoolConfig{
-			Threshold: m.config.DefaultThreshold,
-			Window:    m.config.DefaultWindow,
+// evaluatePools arms interception when a pool crosses its threshold and releases
+// auto-armed interception once all pools have stayed below their thresholds long enough.
+// Every replica tracks the threshold state, but only the leader arms and releases;
+// followers pick the result up from the state store. Must be called with the lock held.
+func (m *NodeMonitor) evaluatePools() {
+	now := m.clock.Now()
+	var reasons []string
+	for _, pool := range m.pools {
+		count := pool.notReadyWithinWindow(now)
+		threshold := m.resolveThreshold(pool)
+		if count >= threshold {
+			reasons = append(reasons, fmt.Sprintf("node pool %s has %d NotReady nodes within %v (threshold %d)",
+				pool.config.Name, count, pool.config.Window.Duration, threshold))
 		}
 	}
 
-	// Calculate the number of NotReady nodes within the window
-	count := 0
-	now := time.Now()
-	klog.Infof("Current time: %v, Time window: %v", now, poolConfig.Window)
-	klog.Infof("Current NotReady nodes: %v", m.getNotReadyNodeNames())
+	if len(reasons) > 0 {
+		// Only arm once per storm, so a release by the operator is not undone by the next
+		// node event of the same storm. A replica that becomes leader during a storm has not
+		// evaluated arming yet and arms at its next evaluation. Pools are not armed during
+		// the initial list, percentage thresholds would resolve against a partial pool.
+		if !m.armEvaluated && m.synced.Load() && m.elector.IsLeader() {
+			m.armEvaluated = true
+			if m.config.AutoArm {
+				reason := strings.Join(reasons, "; ")
+				if m.callback.Arm(handler.ArmedByAuto, reason) {
+					klog.Warningf("Interception armed automatically: %s", reason)
+					go m.recorder.LeaderEventf(context.Background(), m.stateObjectReference(),
+						v1.EventTypeWarning, "InterceptionArmed", "Interception armed automatically: %s", reason)
+				}
+			}
+		}
+		m.overThreshold = true
+		return
+	}
 
-	for nodeName, ts := range m.notReadyNodes {
-		timeSinceNotReady := now.Sub(ts)
-		if timeSinceNotReady < poolConfig.Window {
-			count++
-			klog.Infof("Node %s has been NotReady for %v (within window of %v)",
-				nodeName, timeSinceNotReady, poolConfig.Window)
-		} else {
-			klog.Infof("Node %s has been NotReady for %v (outside window of %v)",
-				nodeName, timeSinceNotReady, poolConfig.Window)
+	m.armEvaluated = false
+	if m.overThreshold {
+		m.overThreshold = false
+		m.belowSince = now
+		klog.Infof("All node pools are below their thresholds")
+	}
+
+	if m.config.AutoReleaseAfter > 0 && !m.belowSince.IsZero() &&
+		now.Sub(m.belowSince) >= m.config.AutoReleaseAfter &&
+		m.callback.ArmedBy() == handler.ArmedByAuto && m.elector.IsLeader() {
+		if m.callback.Disarm() {
+			klog.Infof("Interception released automatically after all node pools stayed below their thresholds for %v",
+				m.config.AutoReleaseAfter)
+			go m.recorder.LeaderEventf(context.Background(), m.stateObjectReference(),
+				v1.EventTypeNormal, "InterceptionReleased",
+				"Interception released automatically after all node pools stayed below their thresholds for %v",
+				m.config.AutoReleaseAfter)
 		}
 	}
-	klog.Infof("Total NotReady nodes within window: %d, threshold: %d", count, poolConfig.Threshold)
+}
 
-	// Update metrics
-	nodeNotReadyCount.Set(float64(count))
+// stateObjectReference returns the object that interception state events are attached to
+func (m *NodeMonitor) stateObjectReference() v1.ObjectReference {
+	return v1.ObjectReference{
+		Kind:       "ConfigMap",
+		APIVersion: "v1",
+		Namespace:  m.config.Namespace,
+		Name:       m.config.StateConfigMap,
+	}
+}
 
-	shouldIntercept := count >= poolConfig.Threshold
-	klog.Infof("Should intercept eviction for pod %s/%s: %v",
-		pod.Namespace, pod.Name, shouldIntercept)
+// resolveThreshold returns the effective threshold of a pool based on its current size,
+// must be called with the lock held
+func (m *NodeMonitor) resolveThreshold(pool *poolState) int {
+	threshold, err := pool.config.ResolveThreshold(pool.nodes)
+	if err != nil {
+		klog.Errorf("Failed to resolve threshold, falling back to default: %v", err)
+		defaultPool := m.defaultPoolConfig()
+		threshold, err = defaultPool.ResolveThreshold(pool.nodes)
+		if err != nil {
+			klog.Errorf("Failed to resolve default threshold: %v", err)
+			return 1
+		}
+	}
+	return threshold
+}
+
+// SetPolicyPools replaces the node pools defined by EvictionProtectionPolicy resources
+func (m *NodeMonitor) SetPolicyPools(pools []config.NodePoolConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.policyPools = pools
+	klog.Infof("Updated policy node pools, %d policy pools and %d configured pools",
+		len(m.policyPools), len(m.filePools))
+	m.reassignPools()
+}
+
+// SetFilePools atomically replaces the node pools loaded from the config file
+func (m *NodeMonitor) SetFilePools(pools []config.NodePoolConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	return shouldIntercept
+	m.filePools = pools
+	klog.Infof("Updated configured node pools, %d policy pools and %d configured pools",
+		len(m.policyPools), len(m.filePools))
+	m.reassignPools()
 }
 
-// findMatchingNodePool finds matching node pool configuration
-func (m *NodeMonitor) findMatchingNodePool(node *v1.Node) *config.NodePoolConfig {
-	for _, pool := range m.config.NodePools {
-		selector, err := metav1.LabelSelectorAsSelector(&pool.LabelSelector)
+// reassignPools rebuilds the pools and re-evaluates the pool membership of every
+// known node after the pools changed, must be called with the lock held
+func (m *NodeMonitor) reassignPools() {
+	m.rebuildPools()
+	for name, node := range m.nodes {
+		node.pool = m.matchPool(node.labels)
+		node.pool.addNode(name, node)
+	}
+
+	m.evaluatePools()
+	m.updateMetrics()
+}
+
+// rebuildPools compiles the selectors of all node pools into empty pool states,
+// policy pools take precedence over the pools from the config file. Node membership
+// is left to the caller. Must be called with the lock held.
+func (m *NodeMonitor) rebuildPools() {
+	configs := make([]config.NodePoolConfig, 0, len(m.policyPools)+len(m.filePools))
+	configs = append(configs, m.policyPools...)
+	configs = append(configs, m.filePools...)
+
+	m.pools = make([]*poolState, 0, len(configs)+1)
+	m.poolsByName = make(map[string]*poolState, len(configs)+1)
+	for _, poolConfig := range configs {
+		if _, exists := m.poolsByName[poolConfig.Name]; exists {
+			klog.Errorf("Ignoring duplicate node pool %s", poolConfig.Name)
+			continue
+		}
+		pool, err := newPoolState(m.withDefaults(poolConfig))
 		if err != nil {
-			klog.Errorf("Invalid label selector in node pool config: %v", err)
+			klog.Errorf("Ignoring node pool: %v", err)
 			continue
 		}
+		m.pools = append(m.pools, pool)
+		m.poolsByName[poolConfig.Name] = pool
+	}
+	defaultPool := newDefaultPoolState(m.withDefaults(m.defaultPoolConfig()))
+	m.pools = append(m.pools, defaultPool)
+	m.poolsByName[config.DefaultPoolName] = defaultPool
+}
+
+// defaultPoolConfig returns the pool configuration for nodes matching no pool
+func (m *NodeMonitor) defaultPoolConfig() config.NodePoolConfig {
+	return config.NodePoolConfig{
+		Name:      config.DefaultPoolName,
+		Threshold: m.config.DefaultThreshold,
+		Window:    config.Duration{Duration: m.config.DefaultWindow},
+	}
+}
+
+// withDefaults fills the settings a node pool leaves unset from the global config
+func (m *NodeMonitor) withDefaults(pool config.NodePoolConfig) config.NodePoolConfig {
+	if pool.InterceptUpdates == nil {
+		pool.InterceptUpdates = m.config.InterceptUpdates
+		if pool.InterceptUpdates == nil {
+			pool.InterceptUpdates = config.DefaultInterceptUpdates
+		}
+	}
+	if pool.AlwaysAllow == nil {
+		pool.AlwaysAllow = m.config.AlwaysAllow
+	}
+	if pool.InterceptOnly == nil {
+		pool.InterceptOnly = m.config.InterceptOnly
+	}
+	return pool
+}
 
-		if selector.Matches(labels.Set(node.Labels)) {
-			return &pool
+// matchPool returns the first pool matching the node labels, the default pool
+// is last and matches every node. Must be called with the lock held.
+func (m *NodeMonitor) matchPool(nodeLabels labels.Set) *poolState {
+	for _, pool := range m.pools {
+		if pool.matches(nodeLabels) {
+			return pool
 		}
 	}
-	return nil
+	return m.pools[len(m.pools)-1]
 }
 
 // handleNodeAdd handles node addition events
@@ -177,55 +555,158 @@ func (m *NodeMonitor) handleNodeUpdate(oldObj, newObj interface{}) {
 
 // handleNodeDelete handles node deletion events
 func (m *NodeMonitor) handleNodeDelete(obj interface{}) {
-	node := obj.(*v1.Node)
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if node, ok = tombstone.Obj.(*v1.Node); !ok {
+			return
+		}
+	}
+
 	m.mu.Lock()
-	delete(m.notReadyNodes, node.Name)
-	m.mu.Unlock()
+	defer m.mu.Unlock()
+
+	state, exists := m.nodes[node.Name]
+	if !exists {
+		return
+	}
+	if !state.notReadySince.IsZero() {
+		nodeNotReadyDuration.WithLabelValues(state.pool.config.Name).Observe(m.clock.Since(state.notReadySince).Seconds())
+		m.notReadyCount--
+		m.callback.RemoveNotReadyNode(node.Name)
+	}
+	state.pool.removeNode(node.Name)
+	delete(m.nodes, node.Name)
+
+	m.evaluatePools()
+	m.updateMetrics()
 }
 
 // updateNodeStatus updates the node status in our tracking
 func (m *NodeMonitor) updateNodeStatus(node *v1.Node) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	isNotReady := false
+	var notReadySince time.Time
 	var notReadyCondition *v1.NodeCondition
 	for i, condition := range node.Status.Conditions {
 		if condition.Type == v1.NodeReady {
 			if condition.Status != v1.ConditionTrue {
-				isNotReady = true
+				// Use the node's LastTransitionTime as the start time for NotReady
 				notReadyCondition = &node.Status.Conditions[i]
+				notReadySince = condition.LastTransitionTime.Time
 			}
 			break
 		}
 	}
+	nodeLabels := labels.Set(node.Labels)
+	release := parseNodeRelease(node)
 
-	if isNotReady && notReadyCondition != nil {
-		klog.Infof("Node %s is NotReady: Status=%s, Reason=%s, Message=%s, LastTransitionTime=%v",
-			node.Name, notReadyCondition.Status, notReadyCondition.Reason,
-			notReadyCondition.Message, notReadyCondition.LastTransitionTime)
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-		// Use the node's LastTransitionTime as the start time for NotReady
-		notReadyTime := notReadyCondition.LastTransitionTime.Time
-		m.notReadyNodes[node.Name] = notReadyTime
-		m.callback.AddNotReadyNode(node.Name)
-		klog.Infof("Added/Updated node %s in NotReady nodes list with timestamp %v, current count: %d, nodes: %v",
-			node.Name, notReadyTime, len(m.notReadyNodes), m.getNotReadyNodeNames())
+	// Track pool membership of every node so percentage thresholds can be resolved
+	pool := m.matchPool(nodeLabels)
+	state, exists := m.nodes[node.Name]
+	if notReadyCondition != nil && notReadySince.IsZero() {
+		// A NotReady condition without LastTransitionTime counts from when it was first seen
+		if exists && !state.notReadySince.IsZero() {
+			notReadySince = state.notReadySince
+		} else {
+			notReadySince = m.clock.Now()
+		}
+	}
+	if exists {
+		m.recordNodeRelease(node, state.release, release, true)
+		state.release = release
+	} else {
+		m.recordNodeRelease(node, nil, release, false)
+	}
+	if exists && state.pool == pool && state.notReadySince.Equal(notReadySince) {
+		// Nothing that affects decisions changed, e.g. a heartbeat
+		state.labels = nodeLabels
+		return
+	}
+
+	wasNotReady := exists && !state.notReadySince.IsZero()
+	if exists {
+		state.pool.removeNode(node.Name)
 	} else {
-		if _, exists := m.notReadyNodes[node.Name]; exists {
-			delete(m.notReadyNodes, node.Name)
-			m.callback.RemoveNotReadyNode(node.Name)
-			klog.Infof("Removed node %s from NotReady nodes list, current count: %d, remaining nodes: %v",
-				node.Name, len(m.notReadyNodes), m.getNotReadyNodeNames())
+		state = &nodeState{release: release}
+		m.nodes[node.Name] = state
+	}
+	if wasNotReady && notReadyCondition == nil {
+		nodeNotReadyDuration.WithLabelValues(state.pool.config.Name).Observe(m.clock.Since(state.notReadySince).Seconds())
+	}
+	state.labels = nodeLabels
+	state.pool = pool
+	state.notReadySince = notReadySince
+	pool.addNode(node.Name, state)
+
+	switch {
+	case notReadyCondition != nil:
+		if !wasNotReady {
+			m.notReadyCount++
 		}
+		m.callback.AddNotReadyNode(node.Name)
+		klog.Infof("Node %s of pool %s is NotReady since %v: Status=%s, Reason=%s, Message=%s, current count: %d",
+			node.Name, pool.config.Name, notReadySince, notReadyCondition.Status, notReadyCondition.Reason,
+			notReadyCondition.Message, m.notReadyCount)
+	case wasNotReady:
+		m.notReadyCount--
+		m.callback.RemoveNotReadyNode(node.Name)
+		klog.Infof("Node %s of pool %s is Ready again, current NotReady count: %d",
+			node.Name, pool.config.Name, m.notReadyCount)
 	}
+
+	m.evaluatePools()
+	m.updateMetrics()
 }
 
-// getNotReadyNodeNames returns a list of NotReady node names for logging
-func (m *NodeMonitor) getNotReadyNodeNames() []string {
-	names := make([]string, 0, len(m.notReadyNodes))
-	for name := range m.notReadyNodes {
-		names = append(names, name)
+// updateMetrics refreshes the NotReady and per-pool gauges, must be called with the lock held.
+// Per-pool series are set in place so scrapes never miss them, only the series of pools
+// removed by a config reload are deleted.
+func (m *NodeMonitor) updateMetrics() {
+	nodeNotReadyCount.Set(float64(m.notReadyCount))
+
+	now := m.clock.Now()
+	intercepting := m.callback.IsIntercepting()
+	metricPools := make(map[string]struct{}, len(m.pools))
+	for _, pool := range m.pools {
+		metricPools[pool.config.Name] = struct{}{}
+		windowCount := pool.notReadyWithinWindow(now)
+		threshold := m.resolveThreshold(pool)
+		nodePoolNotReadyCount.WithLabelValues(pool.config.Name).Set(float64(len(pool.notReadyNodes)))
+		nodePoolNodeCount.WithLabelValues(pool.config.Name).Set(float64(pool.nodes))
+		nodePoolNotReadyWindowCount.WithLabelValues(pool.config.Name).Set(float64(windowCount))
+		nodePoolThreshold.WithLabelValues(pool.config.Name).Set(float64(threshold))
+		armed := 0.0
+		if m.poolArmed(pool, intercepting, windowCount, threshold) {
+			armed = 1
+		}
+		nodePoolArmed.WithLabelValues(pool.config.Name).Set(armed)
+	}
+
+	for name := range m.metricPools {
+		if _, exists := metricPools[name]; exists {
+			continue
+		}
+		nodePoolNotReadyCount.DeleteLabelValues(name)
+		nodePoolNodeCount.DeleteLabelValues(name)
+		nodePoolNotReadyWindowCount.DeleteLabelValues(name)
+		nodePoolThreshold.DeleteLabelValues(name)
+		nodePoolArmed.DeleteLabelValues(name)
+	}
+	m.metricPools = metricPools
+}
+
+// poolArmed reports whether evictions on a pool are intercepted: interception is armed,
+// the pool is neither in audit mode nor released, and it has reached its threshold.
+// Must be called with the lock held.
+func (m *NodeMonitor) poolArmed(pool *poolState, intercepting bool, count, threshold int) bool {
+	if !intercepting || pool.audit() || count < threshold {
+		return false
 	}
-	return names
+	_, released := m.callback.Released(state.ScopePool, pool.config.Name)
+	return !released
 }
@@ -0,0 +1,856 @@
+package monitor
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/kbsonlong/webhook/pkg/config"
+	"github.com/kbsonlong/webhook/pkg/events"
+	"github.com/kbsonlong/webhook/pkg/handler"
+	"github.com/kbsonlong/webhook/pkg/leader"
+	"github.com/kbsonlong/webhook/pkg/state"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// testNode returns a node with the given labels whose Ready condition has the given
+// status since the given time
+func testNode(name string, labels map[string]string, ready v1.ConditionStatus, since time.Time) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{
+				Type:               v1.NodeReady,
+				Status:             ready,
+				LastTransitionTime: metav1.NewTime(since),
+			}},
+		},
+	}
+}
+
+// nodeController is the requester of the evictions made by the node lifecycle controller
+var nodeController = authenticationv1.UserInfo{
+	Username: "system:serviceaccount:kube-system:node-controller",
+	Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:kube-system"},
+}
+
+// testPod returns a pod scheduled on the given node
+func testPod(nodeName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0"},
+		Spec:       v1.PodSpec{NodeName: nodeName},
+	}
+}
+
+// newTestMonitor returns a monitor driven by a fake clock, interception is armed when armed is set
+func newTestMonitor(cfg *config.Config, armed bool, now time.Time) (*NodeMonitor, *handler.CallbackHandler, *clocktesting.FakePassiveClock) {
+	callback := handler.NewCallbackHandler()
+	if armed {
+		callback.Arm(handler.ArmedByCallback, "test")
+	}
+	m := NewNodeMonitor(nil, cfg, callback, nil, nil)
+	fakeClock := clocktesting.NewFakePassiveClock(now)
+	m.SetClock(fakeClock)
+	// Nodes observed by tests are past the initial list
+	m.synced.Store(true)
+	return m, callback, fakeClock
+}
+
+func TestShouldInterceptEviction(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		NodePools: []config.NodePoolConfig{{
+			Name:          "gpu",
+			LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pool": "gpu"}},
+			Threshold:     intstr.FromString("50%"),
+			Window:        config.Duration{Duration: 10 * time.Minute},
+		}},
+		DefaultThreshold: intstr.FromInt(3),
+		DefaultWindow:    5 * time.Minute,
+	}
+	gpu := map[string]string{"pool": "gpu"}
+
+	tests := []struct {
+		name       string
+		armed      bool
+		nodeName   string
+		wantPool   string
+		wantCode   string
+		wantCount  int
+		wantIntcpt bool
+	}{
+		{name: "interception disabled", armed: false, nodeName: "gpu-1", wantPool: "", wantCode: ReasonInterceptionDisabled},
+		{name: "pod without node", armed: true, nodeName: "", wantCode: ReasonNoNode},
+		{name: "unknown node", armed: true, nodeName: "missing", wantCode: ReasonNodeUnknown},
+		{name: "ready node", armed: true, nodeName: "gpu-3", wantPool: "gpu", wantCode: ReasonNodeReady, wantCount: 2},
+		{name: "pool reaching threshold", armed: true, nodeName: "gpu-1", wantPool: "gpu", wantCode: ReasonThresholdReached, wantCount: 2, wantIntcpt: true},
+		{name: "default pool below threshold", armed: true, nodeName: "node-1", wantPool: config.DefaultPoolName, wantCode: ReasonBelowThreshold, wantCount: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _, _ := newTestMonitor(cfg, tt.armed, now)
+			m.ObserveNode(testNode("gpu-1", gpu, v1.ConditionFalse, now.Add(-time.Minute)))
+			m.ObserveNode(testNode("gpu-2", gpu, v1.ConditionUnknown, now.Add(-2*time.Minute)))
+			m.ObserveNode(testNode("gpu-3", gpu, v1.ConditionTrue, now.Add(-time.Hour)))
+			m.ObserveNode(testNode("gpu-4", gpu, v1.ConditionTrue, now.Add(-time.Hour)))
+			m.ObserveNode(testNode("node-1", nil, v1.ConditionFalse, now.Add(-time.Minute)))
+			m.ObserveNode(testNode("node-2", nil, v1.ConditionTrue, now.Add(-time.Hour)))
+
+			got := m.ShouldInterceptEviction(testPod(tt.nodeName), nodeController)
+			if got.Code != tt.wantCode {
+				t.Errorf("code = %s, want %s (%s)", got.Code, tt.wantCode, got.Reason)
+			}
+			if got.Intercept != tt.wantIntcpt {
+				t.Errorf("intercept = %v, want %v", got.Intercept, tt.wantIntcpt)
+			}
+			if tt.wantPool != "" && got.Pool != tt.wantPool {
+				t.Errorf("pool = %s, want %s", got.Pool, tt.wantPool)
+			}
+			if tt.wantCode != ReasonInterceptionDisabled && got.NotReadyCount != tt.wantCount {
+				t.Errorf("NotReady count = %d, want %d", got.NotReadyCount, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestWindowExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		DefaultThreshold: intstr.FromInt(2),
+		DefaultWindow:    5 * time.Minute,
+	}
+	m, _, fakeClock := newTestMonitor(cfg, true, now)
+	m.ObserveNode(testNode("node-1", nil, v1.ConditionFalse, now.Add(-4*time.Minute)))
+	m.ObserveNode(testNode("node-2", nil, v1.ConditionFalse, now.Add(-time.Minute)))
+
+	if got := m.ShouldInterceptEviction(testPod("node-1"), nodeController); !got.Intercept {
+		t.Fatalf("expected interception within the window, got %s", got.Reason)
+	}
+
+	// node-1 leaves the window, only node-2 is counted
+	fakeClock.SetTime(now.Add(90 * time.Second))
+	got := m.ShouldInterceptEviction(testPod("node-1"), nodeController)
+	if got.Intercept || got.Code != ReasonBelowThreshold || got.NotReadyCount != 1 {
+		t.Errorf("after node-1 left the window: intercept = %v, code = %s, count = %d",
+			got.Intercept, got.Code, got.NotReadyCount)
+	}
+
+	// Both nodes leave the window
+	fakeClock.SetTime(now.Add(10 * time.Minute))
+	if got := m.ShouldInterceptEviction(testPod("node-2"), nodeController); got.NotReadyCount != 0 {
+		t.Errorf("NotReady count after the window = %d, want 0", got.NotReadyCount)
+	}
+}
+
+func TestAutoArmAndRelease(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		DefaultThreshold: intstr.FromInt(2),
+		DefaultWindow:    5 * time.Minute,
+		AutoArm:          true,
+		AutoReleaseAfter: 10 * time.Minute,
+	}
+	m, callback, fakeClock := newTestMonitor(cfg, false, now)
+
+	m.ObserveNode(testNode("node-1", nil, v1.ConditionFalse, now))
+	if callback.IsIntercepting() {
+		t.Fatal("interception armed below the threshold")
+	}
+	m.ObserveNode(testNode("node-2", nil, v1.ConditionFalse, now))
+	if !callback.IsIntercepting() || callback.ArmedBy() != handler.ArmedByAuto {
+		t.Fatalf("interception not armed automatically, armedBy = %q", callback.ArmedBy())
+	}
+
+	// A release by the operator is not undone while the pool stays over its threshold
+	callback.Disarm()
+	m.ObserveNode(testNode("node-3", nil, v1.ConditionFalse, now))
+	if callback.IsIntercepting() {
+		t.Fatal("interception re-armed during the same storm")
+	}
+
+	// Arm again, then let the nodes leave the window
+	callback.Arm(handler.ArmedByAuto, "test")
+	fakeClock.SetTime(now.Add(6 * time.Minute))
+	m.mu.Lock()
+	m.evaluatePools()
+	m.mu.Unlock()
+	if !callback.IsIntercepting() {
+		t.Fatal("interception released before AutoReleaseAfter")
+	}
+
+	fakeClock.SetTime(now.Add(17 * time.Minute))
+	m.mu.Lock()
+	m.evaluatePools()
+	m.mu.Unlock()
+	if callback.IsIntercepting() {
+		t.Fatal("interception not released after AutoReleaseAfter")
+	}
+}
+
+func TestAutoArmAfterBecomingLeader(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		DefaultThreshold: intstr.FromInt(2),
+		DefaultWindow:    5 * time.Minute,
+		AutoArm:          true,
+	}
+	m, callback, _ := newTestMonitor(cfg, false, now)
+	// An elector that has not won the election yet
+	m.elector = &leader.Elector{}
+
+	m.ObserveNode(testNode("node-1", nil, v1.ConditionFalse, now))
+	m.ObserveNode(testNode("node-2", nil, v1.ConditionFalse, now))
+	if callback.IsIntercepting() {
+		t.Fatal("follower armed interception")
+	}
+
+	// The replica becomes leader in the middle of the storm
+	m.elector = nil
+	m.mu.Lock()
+	m.evaluatePools()
+	m.mu.Unlock()
+	if !callback.IsIntercepting() || callback.ArmedBy() != handler.ArmedByAuto {
+		t.Fatalf("interception not armed after becoming leader, armedBy = %q", callback.ArmedBy())
+	}
+
+	// As leader it still arms only once per storm
+	callback.Disarm()
+	m.ObserveNode(testNode("node-3", nil, v1.ConditionFalse, now))
+	if callback.IsIntercepting() {
+		t.Fatal("interception re-armed during the same storm")
+	}
+}
+
+func TestNoAutoArmDuringInitialList(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	gpu := map[string]string{"pool": "gpu"}
+	cfg := &config.Config{
+		NodePools: []config.NodePoolConfig{{
+			Name:          "gpu",
+			LabelSelector: metav1.LabelSelector{MatchLabels: gpu},
+			Threshold:     intstr.FromString("20%"),
+			Window:        config.Duration{Duration: 5 * time.Minute},
+		}},
+		DefaultThreshold: intstr.FromInt(3),
+		DefaultWindow:    5 * time.Minute,
+		AutoArm:          true,
+	}
+	m, callback, _ := newTestMonitor(cfg, false, now)
+	m.synced.Store(false)
+
+	// The initial list delivers the NotReady node first, the 20% threshold of the
+	// partial pool resolves to 1
+	m.handleNodeAdd(testNode("gpu-0", gpu, v1.ConditionFalse, now))
+	for i := 1; i < 10; i++ {
+		m.handleNodeAdd(testNode(fmt.Sprintf("gpu-%d", i), gpu, v1.ConditionTrue, now))
+	}
+	m.markSynced()
+	if callback.IsIntercepting() {
+		t.Fatalf("interception armed during the initial list: %s", callback.ArmedBy())
+	}
+	if status := m.PoolStatuses()["gpu"]; status.NotReadyCount != 1 || status.Threshold != 2 {
+		t.Errorf("count = %d, threshold = %d, want 1 and 2", status.NotReadyCount, status.Threshold)
+	}
+
+	// The real storm still arms
+	m.ObserveNode(testNode("gpu-1", gpu, v1.ConditionFalse, now))
+	if !callback.IsIntercepting() || callback.ArmedBy() != handler.ArmedByAuto {
+		t.Fatalf("interception not armed at the threshold, armedBy = %q", callback.ArmedBy())
+	}
+}
+
+func TestNotReadyWithoutTransitionTime(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		DefaultThreshold: intstr.FromInt(3),
+		DefaultWindow:    5 * time.Minute,
+	}
+	m, _, fakeClock := newTestMonitor(cfg, true, now)
+
+	node := testNode("node-1", nil, v1.ConditionFalse, now)
+	node.Status.Conditions[0].LastTransitionTime = metav1.Time{}
+	m.ObserveNode(node)
+	fakeClock.SetTime(now.Add(time.Minute))
+	m.ObserveNode(node)
+	if status := m.PoolStatuses()[config.DefaultPoolName]; status.NotReadyCount != 1 || m.notReadyCount != 1 {
+		t.Fatalf("pool count = %d, global count = %d, want 1", status.NotReadyCount, m.notReadyCount)
+	}
+	if since := m.nodes["node-1"].notReadySince; !since.Equal(now) {
+		t.Errorf("notReadySince = %v, want the time the node was first seen NotReady %v", since, now)
+	}
+
+	m.ObserveNode(testNode("node-1", nil, v1.ConditionTrue, now))
+	if status := m.PoolStatuses()[config.DefaultPoolName]; status.NotReadyCount != 0 || m.notReadyCount != 0 {
+		t.Errorf("after Ready: pool count = %d, global count = %d, want 0", status.NotReadyCount, m.notReadyCount)
+	}
+}
+
+func TestStartWithFakeClient(t *testing.T) {
+	now := time.Now()
+	client := fake.NewClientset(
+		testNode("node-1", nil, v1.ConditionFalse, now),
+		testNode("node-2", nil, v1.ConditionTrue, now),
+	)
+	cfg := &config.Config{
+		DefaultThreshold: intstr.FromInt(2),
+		DefaultWindow:    5 * time.Minute,
+	}
+	callback := handler.NewCallbackHandler()
+	callback.Arm(handler.ArmedByCallback, "test")
+	m := NewNodeMonitor(client, cfg, callback, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.Start(ctx); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	if !m.HasSynced() {
+		t.Fatal("monitor not synced after Start")
+	}
+	if got := m.ShouldInterceptEviction(testPod("node-1"), nodeController); got.Code != ReasonBelowThreshold {
+		t.Fatalf("code = %s, want %s", got.Code, ReasonBelowThreshold)
+	}
+
+	// node-2 becomes NotReady, the informer event brings the pool to its threshold
+	_, err := client.CoreV1().Nodes().Update(ctx, testNode("node-2", nil, v1.ConditionFalse, now), metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("failed to update node: %v", err)
+	}
+	err = wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return m.ShouldInterceptEviction(testPod("node-1"), nodeController).Intercept, nil
+	})
+	if err != nil {
+		t.Fatalf("node update not observed: %v", err)
+	}
+
+	// Deleting a NotReady node drops it from the pool
+	if err := client.CoreV1().Nodes().Delete(ctx, "node-1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete node: %v", err)
+	}
+	err = wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return m.ShouldInterceptEviction(testPod("node-1"), nodeController).Code == ReasonNodeUnknown, nil
+	})
+	if err != nil {
+		t.Fatalf("node deletion not observed: %v", err)
+	}
+}
+
+func TestSetFilePoolsReassignsNodes(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		DefaultThreshold: intstr.FromInt(5),
+		DefaultWindow:    5 * time.Minute,
+	}
+	m, _, _ := newTestMonitor(cfg, true, now)
+	gpu := map[string]string{"pool": "gpu"}
+	m.ObserveNode(testNode("gpu-1", gpu, v1.ConditionFalse, now))
+	m.ObserveNode(testNode("gpu-2", gpu, v1.ConditionTrue, now))
+
+	if got := m.ShouldInterceptEviction(testPod("gpu-1"), nodeController); got.Pool != config.DefaultPoolName || got.Intercept {
+		t.Fatalf("before reload: pool = %s, intercept = %v", got.Pool, got.Intercept)
+	}
+
+	m.SetFilePools([]config.NodePoolConfig{{
+		Name:          "gpu",
+		LabelSelector: metav1.LabelSelector{MatchLabels: gpu},
+		Threshold:     intstr.FromString("50%"),
+		Window:        config.Duration{Duration: 5 * time.Minute},
+	}})
+
+	got := m.ShouldInterceptEviction(testPod("gpu-1"), nodeController)
+	if got.Pool != "gpu" || !got.Intercept || got.Threshold != 1 {
+		t.Errorf("after reload: pool = %s, intercept = %v, threshold = %d", got.Pool, got.Intercept, got.Threshold)
+	}
+	statuses := m.PoolStatuses()
+	if statuses["gpu"].TotalNodes != 2 || statuses[config.DefaultPoolName].TotalNodes != 0 {
+		t.Errorf("pool statuses = %+v", statuses)
+	}
+	if armed := testutil.ToFloat64(nodePoolArmed.WithLabelValues("gpu")); armed != 1 {
+		t.Errorf("node_pool_armed{pool=gpu} = %v, want 1", armed)
+	}
+
+	// Removing the pool deletes its series, the default pool keeps its own
+	m.SetFilePools(nil)
+	if nodePoolThreshold.DeleteLabelValues("gpu") {
+		t.Error("series of the removed pool gpu still exported")
+	}
+	if threshold := testutil.ToFloat64(nodePoolThreshold.WithLabelValues(config.DefaultPoolName)); threshold != 5 {
+		t.Errorf("node_pool_threshold{pool=default} = %v, want 5", threshold)
+	}
+}
+
+func TestRequesterLists(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	admin := authenticationv1.UserInfo{Username: "alice", Groups: []string{"system:masters", "system:authenticated"}}
+	descheduler := authenticationv1.UserInfo{Username: "system:serviceaccount:kube-system:descheduler"}
+	cfg := &config.Config{
+		NodePools: []config.NodePoolConfig{{
+			Name:          "gpu",
+			LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pool": "gpu"}},
+			Threshold:     intstr.FromInt(1),
+			Window:        config.Duration{Duration: 5 * time.Minute},
+			InterceptOnly: []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "kube-system", Name: "node-controller"}},
+		}},
+		DefaultThreshold: intstr.FromInt(1),
+		DefaultWindow:    5 * time.Minute,
+		AlwaysAllow:      []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:masters"}},
+	}
+	m, _, _ := newTestMonitor(cfg, true, now)
+	m.ObserveNode(testNode("gpu-1", map[string]string{"pool": "gpu"}, v1.ConditionFalse, now))
+	m.ObserveNode(testNode("node-1", nil, v1.ConditionFalse, now))
+
+	tests := []struct {
+		name       string
+		nodeName   string
+		user       authenticationv1.UserInfo
+		wantCode   string
+		wantIntcpt bool
+	}{
+		{name: "global allow list", nodeName: "node-1", user: admin, wantCode: ReasonRequesterAllowed},
+		{name: "not on the global allow list", nodeName: "node-1", user: descheduler, wantCode: ReasonThresholdReached, wantIntcpt: true},
+		{name: "on the pool intercept-only list", nodeName: "gpu-1", user: nodeController, wantCode: ReasonThresholdReached, wantIntcpt: true},
+		{name: "not on the pool intercept-only list", nodeName: "gpu-1", user: descheduler, wantCode: ReasonRequesterNotIntercepted},
+		{name: "pool inherits the global allow list", nodeName: "gpu-1", user: admin, wantCode: ReasonRequesterAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.ShouldInterceptEviction(testPod(tt.nodeName), tt.user)
+			if got.Code != tt.wantCode || got.Intercept != tt.wantIntcpt {
+				t.Errorf("code = %s, intercept = %v, want %s, %v (%s)",
+					got.Code, got.Intercept, tt.wantCode, tt.wantIntcpt, got.Reason)
+			}
+		})
+	}
+}
+
+func TestModeAnnotations(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		DefaultThreshold: intstr.FromInt(2),
+		DefaultWindow:    5 * time.Minute,
+	}
+	m, _, _ := newTestMonitor(cfg, true, now)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for name, mode := range map[string]string{"protected": ModeProtect, "ignored": ModeIgnore, "audited": ModeAudit, "typo": "protekt"} {
+		_ = indexer.Add(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{ModeAnnotation: mode},
+		}})
+	}
+	m.namespaces = corelisters.NewNamespaceLister(indexer)
+	m.ObserveNode(testNode("node-1", nil, v1.ConditionFalse, now))
+	m.ObserveNode(testNode("node-2", nil, v1.ConditionTrue, now))
+
+	tests := []struct {
+		name       string
+		namespace  string
+		podMode    string
+		nodeName   string
+		wantCode   string
+		wantLevel  string
+		wantIntcpt bool
+		wantAudit  bool
+	}{
+		{name: "pool default below threshold", namespace: "default", nodeName: "node-1", wantCode: ReasonBelowThreshold, wantLevel: LevelPool},
+		{name: "protected namespace", namespace: "protected", nodeName: "node-1", wantCode: ReasonModeProtect, wantLevel: LevelNamespace, wantIntcpt: true},
+		{name: "protected namespace on Ready node", namespace: "protected", nodeName: "node-2", wantCode: ReasonNodeReady, wantLevel: LevelPool},
+		{name: "pod annotation overrides namespace", namespace: "protected", podMode: ModeIgnore, nodeName: "node-1", wantCode: ReasonModeIgnore, wantLevel: LevelPod},
+		{name: "protected pod", namespace: "default", podMode: ModeProtect, nodeName: "node-1", wantCode: ReasonModeProtect, wantLevel: LevelPod, wantIntcpt: true},
+		{name: "invalid pod annotation falls back to namespace", namespace: "protected", podMode: "nope", nodeName: "node-1", wantCode: ReasonModeProtect, wantLevel: LevelNamespace, wantIntcpt: true},
+		{name: "invalid namespace annotation falls back to pool", namespace: "typo", nodeName: "node-1", wantCode: ReasonBelowThreshold, wantLevel: LevelPool},
+		{name: "audited pod that would be intercepted", namespace: "audited", podMode: ModeProtect, nodeName: "node-1", wantCode: ReasonModeProtect, wantLevel: LevelPod, wantIntcpt: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := testPod(tt.nodeName)
+			pod.Namespace = tt.namespace
+			if tt.podMode != "" {
+				pod.Annotations = map[string]string{ModeAnnotation: tt.podMode}
+			}
+			got := m.ShouldInterceptEviction(pod, nodeController)
+			if got.Code != tt.wantCode || got.Level != tt.wantLevel || got.Intercept != tt.wantIntcpt || got.Audit != tt.wantAudit {
+				t.Errorf("code = %s, level = %s, intercept = %v, audit = %v, want %s, %s, %v, %v (%s)",
+					got.Code, got.Level, got.Intercept, got.Audit, tt.wantCode, tt.wantLevel, tt.wantIntcpt, tt.wantAudit, got.Reason)
+			}
+		})
+	}
+
+	// Audit only reports evictions that the pool would intercept
+	m.ObserveNode(testNode("node-2", nil, v1.ConditionFalse, now))
+	pod := testPod("node-1")
+	pod.Namespace = "audited"
+	got := m.ShouldInterceptEviction(pod, nodeController)
+	if got.Intercept || !got.Audit || got.Code != ReasonThresholdReached || got.Level != LevelNamespace {
+		t.Errorf("audited namespace: code = %s, level = %s, intercept = %v, audit = %v",
+			got.Code, got.Level, got.Intercept, got.Audit)
+	}
+}
+
+func TestAuditPool(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	canary := map[string]string{"pool": "canary"}
+	cfg := &config.Config{
+		NodePools: []config.NodePoolConfig{{
+			Name:          "canary",
+			LabelSelector: metav1.LabelSelector{MatchLabels: canary},
+			Threshold:     intstr.FromInt(1),
+			Window:        config.Duration{Duration: 5 * time.Minute},
+			Mode:          config.PoolModeAudit,
+		}},
+		DefaultThreshold: intstr.FromInt(3),
+		DefaultWindow:    5 * time.Minute,
+	}
+	m, _, _ := newTestMonitor(cfg, true, now)
+	m.ObserveNode(testNode("canary-1", canary, v1.ConditionFalse, now))
+
+	got := m.ShouldInterceptEviction(testPod("canary-1"), nodeController)
+	if got.Intercept || !got.Audit || got.Code != ReasonThresholdReached {
+		t.Errorf("intercept = %v, audit = %v, code = %s, want an audited %s",
+			got.Intercept, got.Audit, got.Code, ReasonThresholdReached)
+	}
+
+	// A protected pod is intercepted even on an audited pool
+	pod := testPod("canary-1")
+	pod.Annotations = map[string]string{ModeAnnotation: ModeProtect}
+	if got := m.ShouldInterceptEviction(pod, nodeController); !got.Intercept || got.Audit {
+		t.Errorf("protected pod: intercept = %v, audit = %v", got.Intercept, got.Audit)
+	}
+
+	if mode := m.PoolStatuses()["canary"].Mode; mode != config.PoolModeAudit {
+		t.Errorf("pool status mode = %q, want %q", mode, config.PoolModeAudit)
+	}
+}
+
+func TestReleases(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	gpu := map[string]string{"pool": "gpu"}
+	cfg := &config.Config{
+		NodePools: []config.NodePoolConfig{{
+			Name:          "gpu",
+			LabelSelector: metav1.LabelSelector{MatchLabels: gpu},
+			Threshold:     intstr.FromInt(1),
+			Window:        config.Duration{Duration: 5 * time.Minute},
+		}},
+		DefaultThreshold: intstr.FromInt(1),
+		DefaultWindow:    5 * time.Minute,
+	}
+	m, callback, _ := newTestMonitor(cfg, true, now)
+	m.ObserveNode(testNode("node-1", nil, v1.ConditionFalse, now))
+	m.ObserveNode(testNode("node-2", nil, v1.ConditionFalse, now))
+	m.ObserveNode(testNode("gpu-1", gpu, v1.ConditionFalse, now))
+
+	callback.Release(state.ScopeNode, "node-1", "alice", "", 0)
+	callback.Release(state.ScopePool, "gpu", "alice", "", 0)
+	callback.Release(state.ScopeNamespace, "batch", "alice", "", 0)
+
+	protected := testPod("node-1")
+	protected.Annotations = map[string]string{ModeAnnotation: ModeProtect}
+	batch := testPod("node-2")
+	batch.Namespace = "batch"
+
+	tests := []struct {
+		name      string
+		pod       *v1.Pod
+		intercept bool
+		code      string
+		level     string
+	}{
+		{name: "released node", pod: testPod("node-1"), code: ReasonNodeReleased, level: LevelNode},
+		{name: "protected pod on released node", pod: protected, code: ReasonNodeReleased, level: LevelNode},
+		{name: "released pool", pod: testPod("gpu-1"), code: ReasonPoolReleased, level: LevelPool},
+		{name: "released namespace", pod: batch, code: ReasonNamespaceReleased, level: LevelNamespace},
+		{name: "other node", pod: testPod("node-2"), intercept: true, code: ReasonThresholdReached, level: LevelPool},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.ShouldInterceptEviction(tt.pod, nodeController)
+			if got.Intercept != tt.intercept || got.Code != tt.code || got.Level != tt.level {
+				t.Errorf("intercept = %v, code = %s, level = %s, want %v, %s, %s (%s)",
+					got.Intercept, got.Code, got.Level, tt.intercept, tt.code, tt.level, got.Reason)
+			}
+		})
+	}
+
+	// Protecting the node again restores interception
+	callback.Protect(state.ScopeNode, "node-1")
+	if got := m.ShouldInterceptEviction(testPod("node-1"), nodeController); !got.Intercept {
+		t.Errorf("protected node: intercept = false (%s)", got.Reason)
+	}
+
+	// Pool statuses report released pools as not armed
+	statuses := m.PoolStatuses()
+	if statuses["gpu"].Armed || !statuses[config.DefaultPoolName].Armed {
+		t.Errorf("armed = %v for the released gpu pool and %v for the default pool, want false and true",
+			statuses["gpu"].Armed, statuses[config.DefaultPoolName].Armed)
+	}
+}
+
+// releasedNode returns a NotReady node annotated with the given release annotations,
+// written by kubectl annotate
+func releasedNode(name string, since time.Time, annotations map[string]string) *v1.Node {
+	node := testNode(name, nil, v1.ConditionFalse, since)
+	node.Annotations = annotations
+	at := metav1.NewTime(since.Add(time.Minute))
+	node.ManagedFields = []metav1.ManagedFieldsEntry{
+		{
+			Manager:   "kubelet",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:conditions":{}}}`)},
+		},
+		{
+			Manager:   "kubectl-annotate",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			Time:      &at,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:eviction-protection.io/release":{}}}}`)},
+		},
+	}
+	return node
+}
+
+func TestNodeReleaseAnnotation(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		DefaultThreshold: intstr.FromInt(1),
+		DefaultWindow:    5 * time.Minute,
+	}
+	m, _, fakeClock := newTestMonitor(cfg, true, now)
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		intercept   bool
+	}{
+		{name: "released", annotations: map[string]string{ReleaseAnnotation: "true"}},
+		{name: "released until later", annotations: map[string]string{
+			ReleaseAnnotation: "true", ReleaseUntilAnnotation: "2026-10-16T18:00:00Z"}},
+		{name: "release expired", annotations: map[string]string{
+			ReleaseAnnotation: "true", ReleaseUntilAnnotation: "2026-10-16T11:00:00Z"}, intercept: true},
+		{name: "invalid expiry", annotations: map[string]string{
+			ReleaseAnnotation: "true", ReleaseUntilAnnotation: "tonight"}, intercept: true},
+		{name: "not true", annotations: map[string]string{ReleaseAnnotation: "yes"}, intercept: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeName := fmt.Sprintf("node-%d", i)
+			m.ObserveNode(releasedNode(nodeName, now, tt.annotations))
+
+			pod := testPod(nodeName)
+			pod.Annotations = map[string]string{ModeAnnotation: ModeProtect}
+			got := m.ShouldInterceptEviction(pod, nodeController)
+			if got.Intercept != tt.intercept {
+				t.Fatalf("intercept = %v, want %v (%s)", got.Intercept, tt.intercept, got.Reason)
+			}
+			if !tt.intercept && (got.Code != ReasonNodeReleased || got.Level != LevelNode) {
+				t.Errorf("code = %s, level = %s, want %s at %s level", got.Code, got.Level, ReasonNodeReleased, LevelNode)
+			}
+		})
+	}
+
+	got := m.ShouldInterceptEviction(testPod("node-0"), nodeController)
+	want := "node node-0 is released by annotation eviction-protection.io/release=true set by kubectl-annotate (Update at 2026-10-16T12:01:00Z)"
+	if got.Reason != want {
+		t.Errorf("reason = %q, want %q", got.Reason, want)
+	}
+	if released := m.PoolStatuses()[config.DefaultPoolName].ReleasedNodes; len(released) != 2 {
+		t.Errorf("released nodes = %v, want node-0 and node-1", released)
+	}
+
+	// The time-boxed release runs out
+	fakeClock.SetTime(time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC))
+	if got := m.ShouldInterceptEviction(testPod("node-1"), nodeController); got.Code == ReasonNodeReleased {
+		t.Errorf("expired release still applies: %s", got.Reason)
+	}
+}
+
+func TestNodeReleaseEvents(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	// The fake clientset does not generate names, record the events instead of storing them
+	created := make(chan *v1.Event, 16)
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		event := action.(k8stesting.CreateAction).GetObject().(*v1.Event)
+		created <- event
+		return true, event, nil
+	})
+	callback := handler.NewCallbackHandler()
+	callback.Arm(handler.ArmedByCallback, "test")
+	cfg := &config.Config{DefaultThreshold: intstr.FromInt(1), DefaultWindow: 5 * time.Minute}
+	m := NewNodeMonitor(nil, cfg, callback, events.NewRecorder(clientset, nil), nil)
+	m.SetClock(clocktesting.NewFakePassiveClock(now))
+
+	// Releases present when a node is first observed are not reported again
+	m.ObserveNode(releasedNode("node-1", now, map[string]string{ReleaseAnnotation: "true"}))
+	m.ObserveNode(testNode("node-2", nil, v1.ConditionFalse, now))
+	m.ObserveNode(releasedNode("node-2", now, map[string]string{ReleaseAnnotation: "true"}))
+	m.ObserveNode(testNode("node-2", nil, v1.ConditionFalse, now))
+
+	reasons := make(map[string]string)
+	for len(reasons) < 2 {
+		select {
+		case event := <-created:
+			reasons[event.Reason] = event.InvolvedObject.Name + ": " + event.Message
+		case <-time.After(5 * time.Second):
+			t.Fatalf("events = %v, want NodeReleased and NodeReleaseRemoved", reasons)
+		}
+	}
+	select {
+	case event := <-created:
+		t.Errorf("unexpected %s event for %s", event.Reason, event.InvolvedObject.Name)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if got := reasons["NodeReleased"]; got != "node-2: Evictions of the node's pods are no longer intercepted, "+
+		"released by annotation eviction-protection.io/release=true set by kubectl-annotate (Update at 2026-10-16T12:01:00Z)" {
+		t.Errorf("NodeReleased event = %q", got)
+	}
+	if got := reasons["NodeReleaseRemoved"]; got == "" || got[:7] != "node-2:" {
+		t.Errorf("NodeReleaseRemoved event = %q", got)
+	}
+}
+
+const (
+	benchmarkNodes    = 5000
+	benchmarkNotReady = 500
+	benchmarkPools    = 10
+)
+
+// silenceKlog keeps per-decision logging out of the measurements
+func silenceKlog(b *testing.B) {
+	b.Helper()
+	flags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(flags)
+	_ = flags.Set("logtostderr", "false")
+	_ = flags.Set("alsologtostderr", "false")
+	_ = flags.Set("stderrthreshold", "FATAL")
+	klog.SetOutput(io.Discard)
+}
+
+// newBenchmarkMonitor returns an armed monitor with benchmarkNodes nodes spread over
+// benchmarkPools pools, benchmarkNotReady of them NotReady
+func newBenchmarkMonitor(b *testing.B) *NodeMonitor {
+	b.Helper()
+
+	pools := make([]config.NodePoolConfig, 0, benchmarkPools)
+	for i := 0; i < benchmarkPools; i++ {
+		pools = append(pools, config.NodePoolConfig{
+			Name: fmt.Sprintf("pool-%d", i),
+			LabelSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"pool": fmt.Sprintf("pool-%d", i)},
+			},
+			Threshold: intstr.FromString("5%"),
+			Window:    config.Duration{Duration: time.Hour},
+		})
+	}
+	cfg := &config.Config{
+		NodePools:        pools,
+		DefaultThreshold: intstr.FromInt(3),
+		DefaultWindow:    5 * time.Minute,
+	}
+
+	callback := handler.NewCallbackHandler()
+	callback.Arm(handler.ArmedByCallback, "benchmark")
+	m := NewNodeMonitor(nil, cfg, callback, nil, nil)
+
+	now := time.Now()
+	for i := 0; i < benchmarkNodes; i++ {
+		m.ObserveNode(benchmarkNode(i, i < benchmarkNotReady, now))
+	}
+	return m
+}
+
+// benchmarkNode returns the i-th node of the benchmark cluster
+func benchmarkNode(i int, notReady bool, now time.Time) *v1.Node {
+	status := v1.ConditionTrue
+	if notReady {
+		status = v1.ConditionFalse
+	}
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("node-%d", i),
+			Labels: map[string]string{"pool": fmt.Sprintf("pool-%d", i%benchmarkPools)},
+		},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{
+				Type:               v1.NodeReady,
+				Status:             status,
+				LastTransitionTime: metav1.NewTime(now.Add(-time.Duration(i) * time.Second)),
+			}},
+		},
+	}
+}
+
+// benchmarkPod returns a pod scheduled on the i-th node
+func benchmarkPod(i int) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)},
+		Spec:       v1.PodSpec{NodeName: fmt.Sprintf("node-%d", i%benchmarkNodes)},
+	}
+}
+
+func BenchmarkShouldInterceptEviction(b *testing.B) {
+	silenceKlog(b)
+	m := newBenchmarkMonitor(b)
+
+	b.Run("NotReadyNode", func(b *testing.B) {
+		pod := benchmarkPod(0)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.ShouldInterceptEviction(pod, nodeController)
+		}
+	})
+
+	b.Run("ReadyNode", func(b *testing.B) {
+		pod := benchmarkPod(benchmarkNodes - 1)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.ShouldInterceptEviction(pod, nodeController)
+		}
+	})
+
+	b.Run("Parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				m.ShouldInterceptEviction(benchmarkPod(i), nodeController)
+				i++
+			}
+		})
+	})
+}
+
+func BenchmarkNodeStatusUpdate(b *testing.B) {
+	silenceKlog(b)
+	m := newBenchmarkMonitor(b)
+	now := time.Now()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Flip a node between Ready and NotReady so every event changes the pool state
+		m.ObserveNode(benchmarkNode(benchmarkNotReady, i%2 == 0, now))
+	}
+}
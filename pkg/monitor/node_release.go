@@ -0,0 +1,155 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// Operators confirm a dead node with kubectl by annotating it, which releases the
+// node's pods like the node release of the callback API:
+//
+//	kubectl annotate node node-1 eviction-protection.io/release=true
+//	kubectl annotate node node-1 eviction-protection.io/release-until=2026-10-16T18:00:00Z
+const (
+	// ReleaseAnnotation releases the pods of a node when set to "true"
+	ReleaseAnnotation = "eviction-protection.io/release"
+	// ReleaseUntilAnnotation optionally limits the release to an RFC 3339 time
+	ReleaseUntilAnnotation = "eviction-protection.io/release-until"
+)
+
+// nodeRelease is a release of a node set through its annotations
+type nodeRelease struct {
+	until     time.Time // zero when the release does not expire
+	manager   string    // field manager that last wrote the release annotation
+	operation string    // operation of the field manager, Update or Apply
+	setAt     time.Time // when the field manager last wrote the annotation
+}
+
+// parseNodeRelease returns the release set on a node, nil when the node is not
+// released. A release with an invalid expiry is ignored so the node stays protected.
+func parseNodeRelease(node *v1.Node) *nodeRelease {
+	if node.Annotations[ReleaseAnnotation] != "true" {
+		return nil
+	}
+	release := &nodeRelease{}
+	if value, exists := node.Annotations[ReleaseUntilAnnotation]; exists {
+		until, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			klog.Warningf("Ignoring release of node %s, invalid %s annotation %q: %v",
+				node.Name, ReleaseUntilAnnotation, value, err)
+			return nil
+		}
+		release.until = until
+	}
+	release.manager, release.operation, release.setAt = releaseManager(node)
+	return release
+}
+
+// releaseManager returns the field manager owning the release annotation according to
+// the node's managedFields. Field managers name the client, such as kubectl-annotate,
+// not the user; the user is only recorded in the apiserver audit log.
+func releaseManager(node *v1.Node) (string, string, time.Time) {
+	var manager, operation string
+	var setAt time.Time
+	for _, entry := range node.ManagedFields {
+		if entry.FieldsV1 == nil || !ownsAnnotation(entry.FieldsV1.Raw, ReleaseAnnotation) {
+			continue
+		}
+		var at time.Time
+		if entry.Time != nil {
+			at = entry.Time.Time
+		}
+		if manager == "" || at.After(setAt) {
+			manager, operation, setAt = entry.Manager, string(entry.Operation), at
+		}
+	}
+	if manager == "" {
+		manager = "unknown"
+	}
+	return manager, operation, setAt
+}
+
+// ownsAnnotation reports whether a managedFields entry owns the given annotation
+func ownsAnnotation(fields []byte, annotation string) bool {
+	var set struct {
+		Metadata struct {
+			Annotations map[string]json.RawMessage `json:"f:annotations"`
+		} `json:"f:metadata"`
+	}
+	if err := json.Unmarshal(fields, &set); err != nil {
+		return false
+	}
+	_, owned := set.Metadata.Annotations["f:"+annotation]
+	return owned
+}
+
+// active reports whether the release is in effect at the given time
+func (r *nodeRelease) active(now time.Time) bool {
+	return r != nil && (r.until.IsZero() || now.Before(r.until))
+}
+
+// equal reports whether two releases are the same for decisions
+func (r *nodeRelease) equal(other *nodeRelease) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return r.until.Equal(other.until) && r.manager == other.manager
+}
+
+// describe returns a human readable description of the release
+func (r *nodeRelease) describe() string {
+	description := fmt.Sprintf("annotation %s=true set by %s", ReleaseAnnotation, r.manager)
+	if r.operation != "" {
+		description += fmt.Sprintf(" (%s at %s)", r.operation, r.setAt.UTC().Format(time.RFC3339))
+	}
+	if !r.until.IsZero() {
+		description += " until " + r.until.UTC().Format(time.RFC3339)
+	}
+	return description
+}
+
+// explainNodeAnnotation allows the eviction when the pod's node is released through its
+// annotations, and reports whether it did. Must be called with the lock held.
+func (m *NodeMonitor) explainNodeAnnotation(explanation *Explanation, nodeName string, node *nodeState) bool {
+	if !node.release.active(m.clock.Now()) {
+		return false
+	}
+	explanation.Level = LevelNode
+	explanation.Code = ReasonNodeReleased
+	explanation.Reason = fmt.Sprintf("node %s is released by %s", nodeName, node.release.describe())
+	return true
+}
+
+// recordNodeRelease reports a change of a node's release annotations. Releases found
+// when a node is first observed, e.g. after a restart, are only logged.
+// Must be called with the lock held.
+func (m *NodeMonitor) recordNodeRelease(node *v1.Node, previous, current *nodeRelease, observed bool) {
+	if previous.equal(current) {
+		return
+	}
+	ref := v1.ObjectReference{
+		Kind:       "Node",
+		APIVersion: "v1",
+		Name:       node.Name,
+		UID:        node.UID,
+	}
+
+	if current == nil {
+		klog.Infof("Node %s is protected again, release annotation removed", node.Name)
+		if observed {
+			go m.recorder.LeaderEventf(context.Background(), ref, v1.EventTypeNormal, "NodeReleaseRemoved",
+				"Release annotation %s removed, evictions of the node's pods are intercepted again", ReleaseAnnotation)
+		}
+		return
+	}
+	klog.Infof("Node %s is released by %s", node.Name, current.describe())
+	if observed {
+		go m.recorder.LeaderEventf(context.Background(), ref, v1.EventTypeWarning, "NodeReleased",
+			"Evictions of the node's pods are no longer intercepted, released by %s", current.describe())
+	}
+}
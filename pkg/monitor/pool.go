@@ -0,0 +1,125 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kbsonlong/webhook/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// poolState is the precomputed state of a node pool. The selector is compiled once per
+// config load and the counters are kept up to date from informer events, so admission
+// decisions neither convert selectors nor scan nodes.
+type poolState struct {
+	config           config.NodePoolConfig
+	selector         labels.Selector // nil for the default pool, which matches every node
+	interceptUpdates map[string]bool // pod update classes intercepted on the pool
+	alwaysAllow      *subjectSet     // requesters never intercepted
+	interceptOnly    *subjectSet     // requesters intercepted exclusively, nil for all requesters
+	nodes            int             // number of nodes in the pool
+	notReadyNodes    map[string]time.Time
+	notReadySince    []time.Time // NotReady start times of the pool's nodes, sorted ascending
+}
+
+// nodeState is the tracked state of a single node
+type nodeState struct {
+	labels        labels.Set
+	pool          *poolState
+	notReadySince time.Time    // zero while the node is Ready
+	release       *nodeRelease // release set through the node's annotations, nil when protected
+}
+
+// newPoolState compiles the selector of a node pool, the pool's unset settings must
+// already be filled from the global config
+func newPoolState(pool config.NodePoolConfig) (*poolState, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&pool.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector in node pool %s: %w", pool.Name, err)
+	}
+	p := newDefaultPoolState(pool)
+	p.selector = selector
+	return p, nil
+}
+
+// newDefaultPoolState creates the state of the default pool
+func newDefaultPoolState(pool config.NodePoolConfig) *poolState {
+	p := &poolState{
+		config:           pool,
+		interceptUpdates: make(map[string]bool, len(pool.InterceptUpdates)),
+		alwaysAllow:      newSubjectSet(pool.AlwaysAllow),
+		interceptOnly:    newSubjectSet(pool.InterceptOnly),
+		notReadyNodes:    make(map[string]time.Time),
+	}
+	for _, class := range pool.InterceptUpdates {
+		p.interceptUpdates[class] = true
+	}
+	return p
+}
+
+// audit reports whether the pool only reports the evictions it would intercept
+func (p *poolState) audit() bool {
+	return p.config.Mode == config.PoolModeAudit
+}
+
+// matches reports whether a node with the given labels belongs to the pool
+func (p *poolState) matches(nodeLabels labels.Set) bool {
+	return p.selector == nil || p.selector.Matches(nodeLabels)
+}
+
+// addNode adds a node to the pool
+func (p *poolState) addNode(name string, node *nodeState) {
+	p.nodes++
+	if !node.notReadySince.IsZero() {
+		p.setNotReady(name, node.notReadySince)
+	}
+}
+
+// removeNode removes a node from the pool
+func (p *poolState) removeNode(name string) {
+	p.nodes--
+	p.removeNotReady(name)
+}
+
+// setNotReady records a node of the pool as NotReady since the given time
+func (p *poolState) setNotReady(name string, since time.Time) {
+	if current, exists := p.notReadyNodes[name]; exists {
+		if current.Equal(since) {
+			return
+		}
+		p.removeNotReady(name)
+	}
+	p.notReadyNodes[name] = since
+	i := sort.Search(len(p.notReadySince), func(i int) bool {
+		return p.notReadySince[i].After(since)
+	})
+	p.notReadySince = append(p.notReadySince, time.Time{})
+	copy(p.notReadySince[i+1:], p.notReadySince[i:])
+	p.notReadySince[i] = since
+}
+
+// removeNotReady forgets a NotReady node of the pool
+func (p *poolState) removeNotReady(name string) {
+	since, exists := p.notReadyNodes[name]
+	if !exists {
+		return
+	}
+	delete(p.notReadyNodes, name)
+	i := sort.Search(len(p.notReadySince), func(i int) bool {
+		return !p.notReadySince[i].Before(since)
+	})
+	if i < len(p.notReadySince) {
+		p.notReadySince = append(p.notReadySince[:i], p.notReadySince[i+1:]...)
+	}
+}
+
+// notReadyWithinWindow counts the nodes of the pool that became NotReady within the pool window
+func (p *poolState) notReadyWithinWindow(now time.Time) int {
+	cutoff := now.Add(-p.config.Window.Duration)
+	i := sort.Search(len(p.notReadySince), func(i int) bool {
+		return p.notReadySince[i].After(cutoff)
+	})
+	return len(p.notReadySince) - i
+}
@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kbsonlong/webhook/pkg/handler"
+	"github.com/kbsonlong/webhook/pkg/state"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// LevelNode is the level of decisions made by releasing the pod's node
+const LevelNode = "node"
+
+// explainRelease allows the eviction when the administrator released the named node or
+// pool, and reports whether it did
+func (m *NodeMonitor) explainRelease(explanation *Explanation, scope, name, level string) bool {
+	release, released := m.callback.Released(scope, name)
+	if !released {
+		return false
+	}
+	explanation.Level = level
+	explanation.Code = releaseCodes[scope]
+	explanation.Reason = fmt.Sprintf("%s %s was released by %s at %s",
+		scope, name, release.ReleasedBy, release.ReleasedAt.UTC().Format(time.RFC3339))
+	if !release.ExpiresAt.IsZero() {
+		explanation.Reason += " until " + release.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if release.Reason != "" {
+		explanation.Reason += ": " + release.Reason
+	}
+	return true
+}
+
+// expireReleases re-arms time-boxed disables and protects released scopes again once
+// their TTL ran out. Decisions already ignore expired releases, so only the leader
+// records the expiry and followers pick it up from the state store.
+func (m *NodeMonitor) expireReleases() {
+	if !m.elector.IsLeader() {
+		return
+	}
+	for _, release := range m.callback.Expire() {
+		var message string
+		if release.Scope == handler.ScopeGlobal {
+			message = fmt.Sprintf("Interception re-armed after the disable by %s expired", release.ReleasedBy)
+		} else {
+			message = fmt.Sprintf("Release of %s %s by %s expired, evictions are intercepted again",
+				release.Scope, release.Name, release.ReleasedBy)
+		}
+		if release.Reason != "" {
+			message += fmt.Sprintf(" (reason: %s)", release.Reason)
+		}
+		klog.Infof("%s", message)
+		go m.recorder.LeaderEventf(context.Background(), m.stateObjectReference(),
+			v1.EventTypeNormal, "ReleaseExpired", "%s", message)
+	}
+}
+
+// applyNamespaceRelease allows evictions in namespaces released by the administrator,
+// like node and pool releases it takes precedence over the mode annotations
+func (m *NodeMonitor) applyNamespaceRelease(explanation *Explanation, pod *v1.Pod) {
+	if explanation.Code == ReasonInterceptionDisabled || pod.Namespace == "" {
+		return
+	}
+	if m.explainRelease(explanation, state.ScopeNamespace, pod.Namespace, LevelNamespace) {
+		explanation.Intercept = false
+		explanation.Audit = false
+	}
+}
+
+// releaseCodes are the reason codes of evictions allowed by a release, by scope
+var releaseCodes = map[string]string{
+	state.ScopeNode:      ReasonNodeReleased,
+	state.ScopePool:      ReasonPoolReleased,
+	state.ScopeNamespace: ReasonNamespaceReleased,
+}
+
+// releasedByAdministrator reports whether a decision was made by disabling interception
+// or by a release, both of which the protect annotation must not override
+func releasedByAdministrator(code string) bool {
+	switch code {
+	case ReasonInterceptionDisabled, ReasonNodeReleased, ReasonPoolReleased, ReasonNamespaceReleased:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// subjectSet matches the requester of an admission request against RBAC subjects,
+// service accounts are matched by their username
+type subjectSet struct {
+	users  map[string]bool
+	groups map[string]bool
+}
+
+// newSubjectSet compiles the subjects of a pool, it returns nil for an empty list
+func newSubjectSet(subjects []rbacv1.Subject) *subjectSet {
+	if len(subjects) == 0 {
+		return nil
+	}
+	s := &subjectSet{users: make(map[string]bool), groups: make(map[string]bool)}
+	for _, subject := range subjects {
+		switch subject.Kind {
+		case rbacv1.UserKind:
+			s.users[subject.Name] = true
+		case rbacv1.GroupKind:
+			s.groups[subject.Name] = true
+		case rbacv1.ServiceAccountKind:
+			s.users[fmt.Sprintf("system:serviceaccount:%s:%s", subject.Namespace, subject.Name)] = true
+		}
+	}
+	return s
+}
+
+// matches reports whether the requester is one of the subjects
+func (s *subjectSet) matches(user authenticationv1.UserInfo) bool {
+	if s == nil {
+		return false
+	}
+	if s.users[user.Username] {
+		return true
+	}
+	for _, group := range user.Groups {
+		if s.groups[group] {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRequester lets requests through that the pool does not gate: requesters on the
+// pool's allow list, and requesters missing from its intercept-only list when it has one
+func (m *NodeMonitor) filterRequester(explanation *Explanation, user authenticationv1.UserInfo) {
+	if !explanation.Intercept && !explanation.Audit {
+		return
+	}
+
+	m.mu.RLock()
+	pool := m.poolsByName[explanation.Pool]
+	m.mu.RUnlock()
+	if pool == nil {
+		return
+	}
+
+	switch {
+	case pool.alwaysAllow.matches(user):
+		explanation.Intercept = false
+		explanation.Audit = false
+		explanation.Level = LevelPool
+		explanation.Code = ReasonRequesterAllowed
+		explanation.Reason = fmt.Sprintf("requester %s is always allowed on node pool %s", user.Username, explanation.Pool)
+	case pool.interceptOnly != nil && !pool.interceptOnly.matches(user):
+		explanation.Intercept = false
+		explanation.Audit = false
+		explanation.Level = LevelPool
+		explanation.Code = ReasonRequesterNotIntercepted
+		explanation.Reason = fmt.Sprintf("requester %s is not intercepted on node pool %s", user.Username, explanation.Pool)
+	}
+}
@@ -0,0 +1,100 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Check reports why a component is not ready, or nil when it is
+type Check func() error
+
+// CheckResult is the outcome of a single readiness check
+type CheckResult struct {
+	Name    string `json:"name"`
+	Ready   bool   `json:"ready"`
+	Message string `json:"message,omitempty"`
+}
+
+// Health serves liveness and readiness endpoints. Liveness only reports that the
+// process is serving, readiness runs every registered check.
+type Health struct {
+	mu       sync.RWMutex
+	names    []string
+	checks   map[string]Check
+	warnings map[string]bool // checks whose failures are reported without affecting readiness
+}
+
+// NewHealth creates a Health without any readiness checks
+func NewHealth() *Health {
+	return &Health{
+		checks:   make(map[string]Check),
+		warnings: make(map[string]bool),
+	}
+}
+
+// AddReadyCheck registers a readiness check, checks are reported in registration order
+func (h *Health) AddReadyCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, exists := h.checks[name]; !exists {
+		h.names = append(h.names, name)
+	}
+	h.checks[name] = check
+	delete(h.warnings, name)
+}
+
+// AddWarningCheck registers a check whose failures are reported in the check message
+// while the replica stays ready, for degraded modes the webhook can still serve in
+func (h *Health) AddWarningCheck(name string, check Check) {
+	h.AddReadyCheck(name, check)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.warnings[name] = true
+}
+
+// Ready runs every readiness check and reports whether all of them passed
+func (h *Health) Ready() (bool, []CheckResult) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ready := true
+	results := make([]CheckResult, 0, len(h.names))
+	for _, name := range h.names {
+		result := CheckResult{Name: name, Ready: true}
+		if err := h.checks[name](); err != nil {
+			result.Message = err.Error()
+			if !h.warnings[name] {
+				ready = false
+				result.Ready = false
+			}
+		}
+		results = append(results, result)
+	}
+	return ready, results
+}
+
+// Livez reports the process as alive as long as it can serve requests
+func (h *Health) Livez(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+}
+
+// Readyz reports a JSON breakdown of the readiness checks, with 503 when any of them fails
+func (h *Health) Readyz(w http.ResponseWriter, _ *http.Request) {
+	ready, results := h.Ready()
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "not ready", http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, map[string]interface{}{
+		"status": status,
+		"checks": results,
+	})
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
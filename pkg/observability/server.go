@@ -0,0 +1,56 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
+)
+
+// Server serves metrics, health and profiling endpoints over plain HTTP,
+// separately from the TLS admission listener
+type Server struct {
+	server *http.Server
+}
+
+// NewServer creates a new observability Server listening on the given port
+func NewServer(port int, enablePprof bool, health *Health) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/livez", health.Livez)
+	mux.HandleFunc("/healthz", health.Livez)
+	mux.HandleFunc("/readyz", health.Readyz)
+
+	if enablePprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	return &Server{
+		server: &http.Server{
+			Addr:    fmt.Sprintf(":%d", port),
+			Handler: mux,
+		},
+	}
+}
+
+// Start starts serving in a goroutine
+func (s *Server) Start() {
+	go func() {
+		klog.Infof("Starting observability server on %s", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			klog.Fatalf("Failed to start observability server: %v", err)
+		}
+	}()
+}
+
+// Shutdown gracefully stops the server
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
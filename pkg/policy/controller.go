@@ -0,0 +1,163 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	v1alpha1 "github.com/kbsonlong/webhook/pkg/apis/evictionprotection/v1alpha1"
+	"github.com/kbsonlong/webhook/pkg/config"
+	"github.com/kbsonlong/webhook/pkg/generated/clientset/versioned"
+	"github.com/kbsonlong/webhook/pkg/generated/informers/externalversions"
+	listers "github.com/kbsonlong/webhook/pkg/generated/listers/evictionprotection/v1alpha1"
+	"github.com/kbsonlong/webhook/pkg/handler"
+	"github.com/kbsonlong/webhook/pkg/leader"
+	"github.com/kbsonlong/webhook/pkg/monitor"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// statusInterval is how often the leader writes the policy status
+const statusInterval = 15 * time.Second
+
+// Controller feeds EvictionProtectionPolicy resources into the NodeMonitor and
+// reports the per-pool state back in the policy status
+type Controller struct {
+	client      versioned.Interface
+	factory     externalversions.SharedInformerFactory
+	lister      listers.EvictionProtectionPolicyLister
+	hasSynced   cache.InformerSynced
+	nodeMonitor *monitor.NodeMonitor
+	callback    *handler.CallbackHandler
+	elector     *leader.Elector
+	synced      atomic.Bool
+}
+
+// NewController creates a new policy Controller, elector may be nil when leader election is disabled
+func NewController(client versioned.Interface, nodeMonitor *monitor.NodeMonitor,
+	callback *handler.CallbackHandler, elector *leader.Elector) *Controller {
+	factory := externalversions.NewSharedInformerFactory(client, 0)
+	informer := factory.Evictionprotection().V1alpha1().EvictionProtectionPolicies()
+
+	c := &Controller{
+		client:      client,
+		factory:     factory,
+		lister:      informer.Lister(),
+		hasSynced:   informer.Informer().HasSynced,
+		nodeMonitor: nodeMonitor,
+		callback:    callback,
+		elector:     elector,
+	}
+
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.syncPools() },
+		UpdateFunc: func(oldObj, newObj interface{}) { c.syncPools() },
+		DeleteFunc: func(obj interface{}) { c.syncPools() },
+	})
+	return c
+}
+
+// Start starts the policy informer and the status updater
+func (c *Controller) Start(ctx context.Context) error {
+	c.factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), c.hasSynced) {
+		return fmt.Errorf("failed to sync eviction protection policy cache")
+	}
+	c.syncPools()
+	c.synced.Store(true)
+
+	go wait.UntilWithContext(ctx, c.updateStatuses, statusInterval)
+	return nil
+}
+
+// HasSynced reports whether the policies have been loaded into the NodeMonitor
+func (c *Controller) HasSynced() bool {
+	return c.synced.Load()
+}
+
+// syncPools converts every policy into a node pool and hands them to the NodeMonitor
+func (c *Controller) syncPools() {
+	policies, err := c.lister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list eviction protection policies: %v", err)
+		return
+	}
+
+	// Sort by name so that overlapping selectors match deterministically
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+
+	pools := make([]config.NodePoolConfig, 0, len(policies))
+	for _, p := range policies {
+		pool := PoolFromPolicy(p)
+		if errs := config.ValidateNodePool(&pool, field.NewPath("spec")); len(errs) > 0 {
+			klog.Errorf("Ignoring invalid eviction protection policy %s: %v", p.Name, errs.ToAggregate())
+			continue
+		}
+		pools = append(pools, pool)
+	}
+	c.nodeMonitor.SetPolicyPools(pools)
+}
+
+// updateStatuses writes the current pool state into the status of every policy, leader only
+func (c *Controller) updateStatuses(ctx context.Context) {
+	if !c.elector.IsLeader() {
+		return
+	}
+
+	policies, err := c.lister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list eviction protection policies: %v", err)
+		return
+	}
+
+	statuses := c.nodeMonitor.PoolStatuses()
+	for _, p := range policies {
+		poolStatus, ok := statuses[p.Name]
+		if !ok {
+			continue
+		}
+
+		status := v1alpha1.EvictionProtectionPolicyStatus{
+			ObservedGeneration: p.Generation,
+			TotalNodes:         int32(poolStatus.TotalNodes),
+			NotReadyCount:      int32(poolStatus.NotReadyCount),
+			Threshold:          int32(poolStatus.Threshold),
+			Armed:              poolStatus.Armed,
+			LastUpdateTime:     p.Status.LastUpdateTime,
+		}
+		if status == p.Status {
+			continue
+		}
+		status.LastUpdateTime = metav1.Now()
+
+		updated := p.DeepCopy()
+		updated.Status = status
+		if _, err := c.client.EvictionprotectionV1alpha1().EvictionProtectionPolicies().UpdateStatus(ctx, updated, metav1.UpdateOptions{}); err != nil {
+			klog.Errorf("Failed to update status of eviction protection policy %s: %v", p.Name, err)
+		}
+	}
+}
+
+// PoolFromPolicy converts a policy into a node pool named after the policy
+func PoolFromPolicy(p *v1alpha1.EvictionProtectionPolicy) config.NodePoolConfig {
+	return config.NodePoolConfig{
+		Name:             p.Name,
+		LabelSelector:    *p.Spec.NodeSelector.DeepCopy(),
+		Threshold:        p.Spec.Threshold,
+		MinThreshold:     int(p.Spec.MinThreshold),
+		MaxThreshold:     int(p.Spec.MaxThreshold),
+		Window:           config.Duration{Duration: p.Spec.Window.Duration},
+		InterceptUpdates: p.Spec.InterceptUpdates,
+		AlwaysAllow:      p.Spec.AlwaysAllow,
+		InterceptOnly:    p.Spec.InterceptOnly,
+		Mode:             p.Spec.Mode,
+	}
+}
@@ -0,0 +1,194 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+// stateKey ConfigMap 中保存状态的键
+const stateKey = "state.json"
+
+// State 持久化的拦截状态
+type State struct {
+	Intercepting   bool      `json:"intercepting"`
+	ArmedAt        time.Time `json:"armedAt,omitempty"`
+	ArmedBy        string    `json:"armedBy,omitempty"`
+	ArmedReason    string    `json:"armedReason,omitempty"`
+	DisabledAt     time.Time `json:"disabledAt,omitempty"`     // 通过回调接口禁用拦截的时间
+	DisabledBy     string    `json:"disabledBy,omitempty"`     // 禁用拦截的调用者
+	DisabledReason string    `json:"disabledReason,omitempty"` // 禁用拦截的原因
+	DisabledUntil  time.Time `json:"disabledUntil,omitempty"`  // 禁用拦截的到期时间，零值表示一直禁用
+	NotReadyNodes  []string  `json:"notReadyNodes"`
+	Releases       []Release `json:"releases,omitempty"` // 单独解除拦截的节点、节点池和命名空间
+	UpdatedAt      time.Time `json:"updatedAt"`          // 最近一次修改的时间，用于丢弃过期的状态
+}
+
+// 解除拦截的范围
+const (
+	// ScopeNode 节点上的 Pod
+	ScopeNode = "node"
+	// ScopePool 节点池内节点上的 Pod
+	ScopePool = "pool"
+	// ScopeNamespace 命名空间内的 Pod
+	ScopeNamespace = "namespace"
+)
+
+// Release 单独解除拦截的范围，全局拦截启用时该范围内的 Pod 驱逐仍然被允许
+type Release struct {
+	Scope      string    `json:"scope"`               // 范围，node、pool 或 namespace
+	Name       string    `json:"name"`                // 节点、节点池或命名空间的名称
+	ReleasedAt time.Time `json:"releasedAt"`          // 解除拦截的时间
+	ReleasedBy string    `json:"releasedBy"`          // 解除拦截的调用者
+	Reason     string    `json:"reason,omitempty"`    // 解除拦截的原因
+	ExpiresAt  time.Time `json:"expiresAt,omitempty"` // 到期后恢复拦截，零值表示一直有效
+}
+
+// Expired 检查解除拦截是否已经到期
+func (r Release) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// Store 拦截状态的持久化存储
+type Store interface {
+	// Load 读取状态，状态不存在时返回 nil
+	Load(ctx context.Context) (*State, error)
+	// Save 保存状态
+	Save(ctx context.Context, state *State) error
+	// Watch 监听状态变化，直到 ctx 结束
+	Watch(ctx context.Context, onChange func(*State))
+}
+
+// ConfigMapStore 将拦截状态保存在 ConfigMap 中
+type ConfigMapStore struct {
+	clientset kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// NewConfigMapStore 创建一个新的 ConfigMapStore
+func NewConfigMapStore(clientset kubernetes.Interface, namespace, name string) *ConfigMapStore {
+	return &ConfigMapStore{
+		clientset: clientset,
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+// Load 从 ConfigMap 读取状态
+func (s *ConfigMapStore) Load(ctx context.Context) (*State, error) {
+	cm, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state configmap %s/%s: %w", s.namespace, s.name, err)
+	}
+	return decodeState(cm)
+}
+
+// Save 将状态写入 ConfigMap，ConfigMap 不存在时创建。
+// ConfigMap 中已经保存了更新的状态时不写入，避免尚未同步的副本用旧状态覆盖新状态
+func (s *ConfigMapStore) Save(ctx context.Context, state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s.name,
+					Namespace: s.namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/managed-by": "pod-eviction-protection",
+					},
+				},
+				Data: map[string]string{stateKey: string(data)},
+			}
+			_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Create(ctx, cm, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		current, err := decodeState(cm)
+		if err != nil {
+			klog.Warningf("Overwriting undecodable state in configmap %s/%s: %v", s.namespace, s.name, err)
+		} else if current != nil && current.UpdatedAt.After(state.UpdatedAt) {
+			klog.Infof("Skipping save of interception state updated at %v, configmap %s/%s holds a newer state updated at %v",
+				state.UpdatedAt, s.namespace, s.name, current.UpdatedAt)
+			return nil
+		}
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[stateKey] = string(data)
+		_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// Watch 监听状态 ConfigMap 的变化
+func (s *ConfigMapStore) Watch(ctx context.Context, onChange func(*State)) {
+	factory := informers.NewSharedInformerFactoryWithOptions(s.clientset, 0,
+		informers.WithNamespace(s.namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", s.name).String()
+		}))
+	informer := factory.Core().V1().ConfigMaps().Informer()
+
+	handle := func(obj interface{}) {
+		cm, ok := obj.(*v1.ConfigMap)
+		if !ok {
+			return
+		}
+		state, err := decodeState(cm)
+		if err != nil {
+			klog.Errorf("Failed to decode state from configmap %s/%s: %v", s.namespace, s.name, err)
+			return
+		}
+		if state != nil {
+			onChange(state)
+		}
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: handle,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			handle(newObj)
+		},
+	})
+
+	factory.Start(ctx.Done())
+	<-ctx.Done()
+	factory.Shutdown()
+}
+
+// decodeState 从 ConfigMap 解析状态
+func decodeState(cm *v1.ConfigMap) (*State, error) {
+	data, ok := cm.Data[stateKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var state State
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state: %w", err)
+	}
+	return &state, nil
+}
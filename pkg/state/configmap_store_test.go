@@ -0,0 +1,40 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSaveKeepsNewerState(t *testing.T) {
+	ctx := context.Background()
+	store := NewConfigMapStore(fake.NewClientset(), "default", "pod-eviction-protection-state")
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	// The leader arms interception, then a follower that has not seen it yet saves
+	// its older snapshot
+	armed := &State{Intercepting: true, ArmedBy: "auto", UpdatedAt: now}
+	if err := store.Save(ctx, armed); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	if err := store.Save(ctx, &State{Intercepting: false, UpdatedAt: now.Add(-time.Minute)}); err != nil {
+		t.Fatalf("failed to save stale state: %v", err)
+	}
+
+	got, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if !got.Intercepting || !got.UpdatedAt.Equal(now) {
+		t.Errorf("loaded intercepting = %v updated at %v, want the newer armed state", got.Intercepting, got.UpdatedAt)
+	}
+
+	if err := store.Save(ctx, &State{Intercepting: false, UpdatedAt: now.Add(time.Minute)}); err != nil {
+		t.Fatalf("failed to save newer state: %v", err)
+	}
+	if got, _ := store.Load(ctx); got.Intercepting {
+		t.Error("a newer state was not saved")
+	}
+}
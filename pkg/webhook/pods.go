@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// podLookupTimeout bounds the API request made when a pod is not in the cache yet
+const podLookupTimeout = 2 * time.Second
+
+// PodCache resolves the pods targeted by evictions, which only carry the pod name
+type PodCache struct {
+	clientset kubernetes.Interface
+	lister    corelisters.PodLister
+	synced    atomic.Bool
+}
+
+// NewPodCache creates a pod cache backed by a pod informer
+func NewPodCache(clientset kubernetes.Interface) *PodCache {
+	return &PodCache{clientset: clientset}
+}
+
+// Start starts the pod informer and waits for its cache to sync
+func (p *PodCache) Start(ctx context.Context) error {
+	factory := informers.NewSharedInformerFactory(p.clientset, 0)
+	podInformer := factory.Core().V1().Pods()
+
+	// Only the metadata and the node of a pod are needed for decisions, drop the rest
+	// to keep the cache small on large clusters
+	if err := podInformer.Informer().SetTransform(trimPod); err != nil {
+		return fmt.Errorf("failed to set pod transform: %w", err)
+	}
+	p.lister = podInformer.Lister()
+
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced) {
+		return fmt.Errorf("failed to sync pod cache")
+	}
+	p.synced.Store(true)
+	return nil
+}
+
+// HasSynced reports whether the pod cache has synced
+func (p *PodCache) HasSynced() bool {
+	return p.synced.Load()
+}
+
+// Get returns the pod with the given namespace and name. Pods missing from the cache,
+// such as pods created moments ago, are read from the API server.
+func (p *PodCache) Get(ctx context.Context, namespace, name string) (*v1.Pod, error) {
+	if p.lister != nil {
+		pod, err := p.lister.Pods(namespace).Get(name)
+		if err == nil {
+			return pod, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	klog.V(2).Infof("Pod %s/%s not in cache, reading it from the API server", namespace, name)
+	ctx, cancel := context.WithTimeout(ctx, podLookupTimeout)
+	defer cancel()
+	return p.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// trimPod keeps the fields of a pod used by eviction decisions
+func trimPod(obj interface{}) (interface{}, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return obj, nil
+	}
+	// Informers pass freshly decoded objects, they can be trimmed in place
+	pod.ManagedFields = nil
+	pod.Spec = v1.PodSpec{NodeName: pod.Spec.NodeName}
+	pod.Status = v1.PodStatus{}
+	return pod, nil
+}
@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodCacheTrimsPods(t *testing.T) {
+	pod := testPod("web-0", "node-1")
+	pod.Spec.Containers = []v1.Container{{Name: "app", Image: "nginx"}}
+	pod.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+	pod.Status.Phase = v1.PodRunning
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pods := NewPodCache(fake.NewClientset(pod))
+	if err := pods.Start(ctx); err != nil {
+		t.Fatalf("failed to start pod cache: %v", err)
+	}
+
+	got, err := pods.Get(ctx, "shop", "web-0")
+	if err != nil {
+		t.Fatalf("failed to get pod: %v", err)
+	}
+	if got.Spec.NodeName != "node-1" || got.UID != pod.UID {
+		t.Errorf("pod = %s on %s, want %s on node-1", got.UID, got.Spec.NodeName, pod.UID)
+	}
+	if len(got.Spec.Containers) != 0 || len(got.ManagedFields) != 0 || got.Status.Phase != "" {
+		t.Errorf("cached pod was not trimmed: %+v", got)
+	}
+}
+
+func TestPodCacheFallsBackToAPI(t *testing.T) {
+	ctx := context.Background()
+	// The cache is not started, as for a pod created after the last informer event
+	pods := NewPodCache(fake.NewClientset(testPod("web-0", "node-1")))
+
+	got, err := pods.Get(ctx, "shop", "web-0")
+	if err != nil {
+		t.Fatalf("failed to get pod: %v", err)
+	}
+	if got.Spec.NodeName != "node-1" {
+		t.Errorf("node = %s, want node-1", got.Spec.NodeName)
+	}
+
+	if _, err := pods.Get(ctx, "shop", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("err = %v, want NotFound", err)
+	}
+}
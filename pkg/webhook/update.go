@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"github.com/kbsonlong/webhook/pkg/config"
+	v1 "k8s.io/api/core/v1"
+)
+
+// classifyUpdate returns the class of a pod update, or an empty string when the update
+// is unrelated to eviction, such as label, annotation or finalizer changes
+func classifyUpdate(oldPod, pod *v1.Pod) string {
+	// Graceful deletion sets deletionTimestamp and deletionGracePeriodSeconds
+	if oldPod.DeletionTimestamp == nil && pod.DeletionTimestamp != nil {
+		return config.UpdateClassDeletion
+	}
+	if pod.DeletionGracePeriodSeconds != nil &&
+		(oldPod.DeletionGracePeriodSeconds == nil || *oldPod.DeletionGracePeriodSeconds != *pod.DeletionGracePeriodSeconds) {
+		return config.UpdateClassDeletion
+	}
+
+	// The node lifecycle controller marks the pods of a NotReady node as not ready,
+	// pods of lost nodes may also be failed
+	if podReadyStatus(oldPod) != v1.ConditionFalse && podReadyStatus(pod) == v1.ConditionFalse {
+		return config.UpdateClassStatus
+	}
+	if oldPod.Status.Phase != v1.PodFailed && pod.Status.Phase == v1.PodFailed {
+		return config.UpdateClassStatus
+	}
+
+	return ""
+}
+
+// podReadyStatus returns the status of the Ready condition of a pod
+func podReadyStatus(pod *v1.Pod) v1.ConditionStatus {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status
+		}
+	}
+	return v1.ConditionUnknown
+}
@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/kbsonlong/webhook/pkg/config"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClassifyUpdate(t *testing.T) {
+	gracePeriod := func(seconds int64) *int64 { return &seconds }
+	now := metav1.Now()
+	ready := func(status v1.ConditionStatus) v1.PodStatus {
+		return v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		oldPod v1.Pod
+		pod    v1.Pod
+		want   string
+	}{
+		{
+			name: "deletion timestamp set",
+			pod:  v1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}},
+			want: config.UpdateClassDeletion,
+		},
+		{
+			name:   "deletion grace period shortened",
+			oldPod: v1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now, DeletionGracePeriodSeconds: gracePeriod(30)}},
+			pod:    v1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now, DeletionGracePeriodSeconds: gracePeriod(0)}},
+			want:   config.UpdateClassDeletion,
+		},
+		{
+			name:   "finalizer removed from a terminating pod",
+			oldPod: v1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now, DeletionGracePeriodSeconds: gracePeriod(30), Finalizers: []string{"example.com/cleanup"}}},
+			pod:    v1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now, DeletionGracePeriodSeconds: gracePeriod(30)}},
+			want:   "",
+		},
+		{
+			name:   "pod marked not ready",
+			oldPod: v1.Pod{Status: ready(v1.ConditionTrue)},
+			pod:    v1.Pod{Status: ready(v1.ConditionFalse)},
+			want:   config.UpdateClassStatus,
+		},
+		{
+			name:   "pod failed",
+			oldPod: v1.Pod{Status: v1.PodStatus{Phase: v1.PodRunning}},
+			pod:    v1.Pod{Status: v1.PodStatus{Phase: v1.PodFailed}},
+			want:   config.UpdateClassStatus,
+		},
+		{
+			name:   "status of a not ready pod refreshed",
+			oldPod: v1.Pod{Status: ready(v1.ConditionFalse)},
+			pod:    v1.Pod{Status: ready(v1.ConditionFalse)},
+			want:   "",
+		},
+		{
+			name:   "labels and annotations changed",
+			oldPod: v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"a": "1"}}},
+			pod:    v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"a": "2"}, Annotations: map[string]string{"b": "1"}}},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyUpdate(&tt.oldPod, &tt.pod); got != tt.want {
+				t.Errorf("classifyUpdate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
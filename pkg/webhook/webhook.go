@@ -2,6 +2,7 @@ package webhook
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -10,40 +11,71 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	admissionv1 "k8s.io/api/admission/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
+	"github.com/kbsonlong/webhook/pkg/events"
 	"github.com/kbsonlong/webhook/pkg/monitor"
 )
 
 var (
-	evictionInterceptedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	evictionInterceptedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "eviction_intercepted_total",
 		Help: "Total number of eviction requests intercepted",
-	})
-	evictionAllowedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	}, []string{"operation", "namespace", "pool", "reason"})
+	evictionAllowedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "eviction_allowed_total",
 		Help: "Total number of eviction requests allowed",
-	})
+	}, []string{"operation", "namespace", "pool", "reason"})
+	evictionWouldInterceptTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "eviction_would_intercept_total",
+		Help: "Total number of eviction requests allowed in audit mode that would have been intercepted",
+	}, []string{"operation", "namespace", "pool", "reason"})
+	admissionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "admission_duration_seconds",
+		Help:    "Latency of admission requests handled by the webhook",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"operation", "decision"})
 )
 
+// defaultRetryAfter is the retry hint returned with intercepted evictions
+const defaultRetryAfter = 30 * time.Second
+
 // Webhook handles admission requests
 type Webhook struct {
 	nodeMonitor *monitor.NodeMonitor
-	clientset   *kubernetes.Clientset
+	pods        *PodCache
+	recorder    *events.Recorder
+	retryAfter  time.Duration
 }
 
-// NewWebhook creates a new Webhook instance
-func NewWebhook(nodeMonitor *monitor.NodeMonitor, clientset *kubernetes.Clientset) *Webhook {
+// NewWebhook creates a new Webhook instance, pods resolves the targets of the
+// pods/eviction subresource
+func NewWebhook(nodeMonitor *monitor.NodeMonitor, pods *PodCache, recorder *events.Recorder) *Webhook {
 	return &Webhook{
 		nodeMonitor: nodeMonitor,
-		clientset:   clientset,
+		pods:        pods,
+		recorder:    recorder,
+		retryAfter:  defaultRetryAfter,
 	}
 }
 
+// SetRetryAfter sets how long clients are asked to wait before retrying an intercepted eviction
+func (w *Webhook) SetRetryAfter(retryAfter time.Duration) {
+	w.retryAfter = retryAfter
+}
+
 // HandleAdmission handles admission requests
 func (w *Webhook) HandleAdmission(c *gin.Context) {
+	start := time.Now()
+	decision := "error"
+	var operation admissionv1.Operation
+	defer func() {
+		admissionDuration.WithLabelValues(string(operation), decision).Observe(time.Since(start).Seconds())
+	}()
+
 	var admissionReview admissionv1.AdmissionReview
 	if err := c.ShouldBindJSON(&admissionReview); err != nil {
 		klog.Errorf("Failed to decode admission review: %v", err)
@@ -51,26 +83,69 @@ func (w *Webhook) HandleAdmission(c *gin.Context) {
 		return
 	}
 
+	operation = admissionReview.Request.Operation
+
 	klog.Infof("Received admission request: Operation=%s, Kind=%s, Namespace=%s, Name=%s",
 		admissionReview.Request.Operation,
 		admissionReview.Request.Kind.Kind,
 		admissionReview.Request.Namespace,
 		admissionReview.Request.Name)
 
-	// Only handle DELETE and UPDATE operations for pods
-	if admissionReview.Request.Operation != admissionv1.Delete &&
+	// Only handle DELETE and UPDATE operations for pods, and CREATE on pods/eviction
+	eviction := admissionReview.Request.Operation == admissionv1.Create &&
+		admissionReview.Request.SubResource == "eviction"
+	dryRun := admissionReview.Request.DryRun != nil && *admissionReview.Request.DryRun
+	if !eviction &&
+		admissionReview.Request.Operation != admissionv1.Delete &&
 		admissionReview.Request.Operation != admissionv1.Update {
 		klog.Infof("Ignoring operation %s for %s/%s",
 			admissionReview.Request.Operation,
 			admissionReview.Request.Namespace,
 			admissionReview.Request.Name)
+		decision = "ignored"
 		c.JSON(http.StatusOK, admissionReview)
 		return
 	}
 
 	// Decode the pod being deleted or updated
 	var pod v1.Pod
-	if admissionReview.Request.Operation == admissionv1.Delete {
+	var explanation monitor.Explanation
+	if eviction {
+		var evictionRequest policyv1.Eviction
+		if err := json.Unmarshal(admissionReview.Request.Object.Raw, &evictionRequest); err != nil {
+			klog.Errorf("Failed to decode eviction from Object: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// The eviction only names its pod, the pod is resolved from the cache
+		target, err := w.pods.Get(c.Request.Context(), admissionReview.Request.Namespace, admissionReview.Request.Name)
+		if apierrors.IsNotFound(err) {
+			klog.Infof("Allowing eviction of missing pod %s/%s",
+				admissionReview.Request.Namespace, admissionReview.Request.Name)
+			decision = "allowed"
+			admissionReview.Response = &admissionv1.AdmissionResponse{UID: admissionReview.Request.UID, Allowed: true}
+			c.JSON(http.StatusOK, admissionReview)
+			return
+		}
+		if err != nil {
+			// The pod cannot be resolved, ask the client to retry rather than evict blindly
+			klog.Errorf("Failed to get pod %s/%s for eviction: %v",
+				admissionReview.Request.Namespace, admissionReview.Request.Name, err)
+			admissionReview.Response = &admissionv1.AdmissionResponse{
+				UID:     admissionReview.Request.UID,
+				Allowed: false,
+				Result:  w.retryStatus(admissionReview.Request.Name, fmt.Sprintf("Failed to resolve the evicted pod: %v", err)),
+			}
+			c.JSON(http.StatusOK, admissionReview)
+			return
+		}
+		pod = *target
+		klog.Infof("Processing eviction request for pod %s/%s on node %s, dryRun=%v",
+			pod.Namespace, pod.Name, pod.Spec.NodeName, dryRun)
+
+		explanation = w.nodeMonitor.ShouldInterceptEviction(&pod, admissionReview.Request.UserInfo)
+	} else if admissionReview.Request.Operation == admissionv1.Delete {
 		if err := json.Unmarshal(admissionReview.Request.OldObject.Raw, &pod); err != nil {
 			klog.Errorf("Failed to decode pod from OldObject: %v", err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -78,55 +153,69 @@ func (w *Webhook) HandleAdmission(c *gin.Context) {
 		}
 		klog.Infof("Processing DELETE request for pod %s/%s on node %s",
 			pod.Namespace, pod.Name, pod.Spec.NodeName)
+
+		// Check if we should intercept the eviction
+		explanation = w.nodeMonitor.ShouldInterceptEviction(&pod, admissionReview.Request.UserInfo)
 	} else {
+		var oldPod v1.Pod
 		if err := json.Unmarshal(admissionReview.Request.Object.Raw, &pod); err != nil {
 			klog.Errorf("Failed to decode pod from Object: %v", err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		klog.Infof("Processing UPDATE request for pod %s/%s on node %s",
-			pod.Namespace, pod.Name, pod.Spec.NodeName)
+		if err := json.Unmarshal(admissionReview.Request.OldObject.Raw, &oldPod); err != nil {
+			klog.Errorf("Failed to decode pod from OldObject: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		class := classifyUpdate(&oldPod, &pod)
+		klog.Infof("Processing UPDATE request for pod %s/%s on node %s, subresource=%q, class=%q",
+			pod.Namespace, pod.Name, pod.Spec.NodeName, admissionReview.Request.SubResource, class)
+
+		// Only updates that delete the pod or mark it as lost are treated as evictions
+		explanation = w.nodeMonitor.ShouldInterceptUpdate(&pod, class, admissionReview.Request.UserInfo)
 	}
 
-	// Check if we should intercept the eviction
-	shouldIntercept := w.nodeMonitor.ShouldInterceptEviction(&pod)
-	klog.Infof("Eviction decision for pod %s/%s: shouldIntercept=%v",
-		pod.Namespace, pod.Name, shouldIntercept)
+	shouldIntercept := explanation.Intercept
+	message := decisionMessage(explanation)
+	// The webhook declares sideEffects None, dry runs must not emit events or audit counts
+	klog.Infof("Eviction decision for pod %s/%s: shouldIntercept=%v, level=%s",
+		pod.Namespace, pod.Name, shouldIntercept, explanation.Level)
 
 	// Update metrics
+	labels := prometheus.Labels{
+		"operation": string(operation),
+		"namespace": pod.Namespace,
+		"pool":      explanation.Pool,
+		"reason":    explanation.Code,
+	}
+	podRef := v1.ObjectReference{
+		Kind:      "Pod",
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		UID:       pod.UID,
+	}
 	if shouldIntercept {
-		evictionInterceptedTotal.Inc()
+		decision = "intercepted"
+		evictionInterceptedTotal.With(labels).Inc()
 		// Create event for the pod
-		event := &v1.Event{
-			ObjectMeta: metav1.ObjectMeta{
-				GenerateName: "pod-eviction-protection-",
-				Namespace:    pod.Namespace,
-			},
-			InvolvedObject: v1.ObjectReference{
-				Kind:      "Pod",
-				Name:      pod.Name,
-				Namespace: pod.Namespace,
-				UID:       pod.UID,
-			},
-			Reason:  "EvictionProtection",
-			Message: "Pod eviction intercepted due to node being NotReady. Waiting for administrator confirmation.",
-			Source: v1.EventSource{
-				Component: "pod-eviction-protection",
-			},
-			FirstTimestamp: metav1.NewTime(time.Now()),
-			LastTimestamp:  metav1.NewTime(time.Now()),
-			Count:          1,
-			Type:           "Warning",
+		if !dryRun {
+			w.recorder.Eventf(c.Request.Context(), podRef, v1.EventTypeWarning, "EvictionProtection",
+				"%s. Waiting for administrator confirmation.", message)
 		}
-
-		_, err := w.clientset.CoreV1().Events(pod.Namespace).Create(c.Request.Context(), event, metav1.CreateOptions{})
-		if err != nil {
-			klog.Errorf("Failed to create event for pod %s/%s: %v", pod.Namespace, pod.Name, err)
-		} else {
-			klog.Infof("Created event for pod %s/%s", pod.Namespace, pod.Name)
+	} else if explanation.Audit {
+		// Audit mode allows the request, the reason is the one it would be denied for
+		decision = "audited"
+		klog.Warningf("Audit: eviction of pod %s/%s would be intercepted: %s, dryRun=%v",
+			pod.Namespace, pod.Name, explanation.Reason, dryRun)
+		if !dryRun {
+			evictionWouldInterceptTotal.With(labels).Inc()
+			w.recorder.Eventf(c.Request.Context(), podRef, v1.EventTypeWarning, "EvictionAudited",
+				"Pod eviction would be intercepted at %s level: %s", explanation.Level, explanation.Reason)
 		}
 	} else {
-		evictionAllowedTotal.Inc()
+		decision = "allowed"
+		evictionAllowedTotal.With(labels).Inc()
 	}
 
 	// Prepare the response
@@ -135,18 +224,65 @@ func (w *Webhook) HandleAdmission(c *gin.Context) {
 		Allowed: !shouldIntercept,
 	}
 
-	if shouldIntercept {
+	if shouldIntercept && eviction {
+		// Eviction clients such as kubectl drain and the descheduler retry on 429
+		admissionResponse.Result = w.retryStatus(pod.Name, message)
+		klog.Infof("Denying eviction for pod %s/%s, retry after %v", pod.Namespace, pod.Name, w.retryAfter)
+	} else if shouldIntercept {
 		admissionResponse.Result = &metav1.Status{
 			Status:  "Failure",
-			Message: "Pod eviction intercepted due to multiple nodes being NotReady",
+			Message: message,
 			Reason:  "EvictionProtection",
 			Code:    http.StatusForbidden,
 		}
 		klog.Infof("Denying eviction for pod %s/%s", pod.Namespace, pod.Name)
 	} else {
+		admissionResponse.Result = &metav1.Status{
+			Status:  metav1.StatusSuccess,
+			Message: message,
+			Code:    http.StatusOK,
+		}
+		if explanation.Audit {
+			admissionResponse.Warnings = []string{auditWarning(explanation)}
+		}
 		klog.Infof("Allowing eviction for pod %s/%s", pod.Namespace, pod.Name)
 	}
 
 	admissionReview.Response = admissionResponse
 	c.JSON(http.StatusOK, admissionReview)
 }
+
+// decisionMessage describes a decision and the level that made it: the pod or namespace
+// mode annotation, or the node pool
+func decisionMessage(explanation monitor.Explanation) string {
+	outcome := "allowed"
+	if explanation.Intercept {
+		outcome = "intercepted"
+	}
+	return fmt.Sprintf("Pod eviction %s at %s level: %s", outcome, explanation.Level, explanation.Reason)
+}
+
+// auditWarning returns the warning shown to clients whose request is only allowed because
+// of audit mode, kept short as clients print warnings verbatim
+func auditWarning(explanation monitor.Explanation) string {
+	if explanation.Pool == "" {
+		return fmt.Sprintf("eviction-protection: audit mode, this request would be intercepted (%s)", explanation.Code)
+	}
+	return fmt.Sprintf("eviction-protection: audit mode, this request would be intercepted on node pool %s (%s)",
+		explanation.Pool, explanation.Code)
+}
+
+// retryStatus returns a 429 status asking the client to retry the eviction later
+func (w *Webhook) retryStatus(name, message string) *metav1.Status {
+	return &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Message: message,
+		Reason:  metav1.StatusReasonTooManyRequests,
+		Code:    http.StatusTooManyRequests,
+		Details: &metav1.StatusDetails{
+			Name:              name,
+			Kind:              "pods",
+			RetryAfterSeconds: int32(w.retryAfter.Seconds()),
+		},
+	}
+}
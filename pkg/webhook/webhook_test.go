@@ -0,0 +1,343 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kbsonlong/webhook/pkg/config"
+	"github.com/kbsonlong/webhook/pkg/handler"
+	"github.com/kbsonlong/webhook/pkg/monitor"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestRouter returns a router serving a webhook whose monitor knows node-1 and
+// node-2 as NotReady and node-3 as Ready, with a threshold of 2 NotReady nodes.
+// The pod cache holds shop/web-0 on node-1 and shop/web-1 on node-3.
+// interceptUpdates nil intercepts the default update classes.
+func newTestRouter(t *testing.T, armed bool, interceptUpdates []string) *gin.Engine {
+	t.Helper()
+	return newTestRouterWithConfig(t, armed, &config.Config{
+		DefaultThreshold: intstr.FromInt(2),
+		DefaultWindow:    5 * time.Minute,
+		InterceptUpdates: interceptUpdates,
+	})
+}
+
+// newTestRouterWithConfig is newTestRouter with the given config, nodes carry their
+// name in the kubernetes.io/hostname label
+func newTestRouterWithConfig(t *testing.T, armed bool, cfg *config.Config) *gin.Engine {
+	t.Helper()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	callback := handler.NewCallbackHandler()
+	if armed {
+		callback.Arm(handler.ArmedByCallback, "test")
+	}
+	nodeMonitor := monitor.NewNodeMonitor(nil, cfg, callback, nil, nil)
+	nodeMonitor.SetClock(clocktesting.NewFakePassiveClock(now))
+	for name, ready := range map[string]v1.ConditionStatus{
+		"node-1": v1.ConditionFalse,
+		"node-2": v1.ConditionUnknown,
+		"node-3": v1.ConditionTrue,
+	} {
+		nodeMonitor.ObserveNode(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"kubernetes.io/hostname": name}},
+			Status: v1.NodeStatus{Conditions: []v1.NodeCondition{{
+				Type:               v1.NodeReady,
+				Status:             ready,
+				LastTransitionTime: metav1.NewTime(now.Add(-time.Minute)),
+			}}},
+		})
+	}
+
+	pods := NewPodCache(fake.NewClientset(testPod("web-0", "node-1"), testPod("web-1", "node-3")))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := pods.Start(ctx); err != nil {
+		t.Fatalf("failed to start pod cache: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/validate", NewWebhook(nodeMonitor, pods, nil).HandleAdmission)
+	return router
+}
+
+// testPod returns a pod of the shop namespace scheduled on the given node
+func testPod(name, nodeName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name, UID: types.UID("pod-" + name)},
+		Spec:       v1.PodSpec{NodeName: nodeName},
+	}
+}
+
+// review posts an AdmissionReview to the router and decodes the response
+func review(t *testing.T, router *gin.Engine, body []byte) (int, *admissionv1.AdmissionReview) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	var resp admissionv1.AdmissionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return rec.Code, &resp
+}
+
+// fixture reads an AdmissionReview fixture from testdata
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return data
+}
+
+func TestHandleAdmission(t *testing.T) {
+	tests := []struct {
+		name             string
+		fixture          string
+		armed            bool
+		interceptUpdates []string
+		wantAllowed      bool
+		wantCode         int32
+		wantRetryAfter   int32
+	}{
+		{
+			name:        "delete on NotReady node is intercepted",
+			fixture:     "delete-pod-on-notready-node.json",
+			armed:       true,
+			wantAllowed: false,
+			wantCode:    http.StatusForbidden,
+		},
+		{
+			name:        "deletion update on NotReady node is intercepted",
+			fixture:     "update-pod-deletion-on-notready-node.json",
+			armed:       true,
+			wantAllowed: false,
+			wantCode:    http.StatusForbidden,
+		},
+		{
+			name:        "status update on NotReady node is intercepted",
+			fixture:     "update-pod-status-on-notready-node.json",
+			armed:       true,
+			wantAllowed: false,
+			wantCode:    http.StatusForbidden,
+		},
+		{
+			name:        "label and finalizer update on NotReady node is allowed",
+			fixture:     "update-pod-labels-on-notready-node.json",
+			armed:       true,
+			wantAllowed: true,
+		},
+		{
+			name:             "status update is allowed when the pool only intercepts deletion",
+			fixture:          "update-pod-status-on-notready-node.json",
+			armed:            true,
+			interceptUpdates: []string{config.UpdateClassDeletion},
+			wantAllowed:      true,
+		},
+		{
+			name:             "deletion update is allowed when the pool intercepts no updates",
+			fixture:          "update-pod-deletion-on-notready-node.json",
+			armed:            true,
+			interceptUpdates: []string{},
+			wantAllowed:      true,
+		},
+		{
+			name:           "eviction on NotReady node asks the client to retry",
+			fixture:        "evict-pod-on-notready-node.json",
+			armed:          true,
+			wantAllowed:    false,
+			wantCode:       http.StatusTooManyRequests,
+			wantRetryAfter: int32(defaultRetryAfter.Seconds()),
+		},
+		{
+			name:        "eviction on Ready node is allowed",
+			fixture:     "evict-pod-on-ready-node.json",
+			armed:       true,
+			wantAllowed: true,
+		},
+		{
+			name:        "eviction of a missing pod is allowed",
+			fixture:     "evict-missing-pod.json",
+			armed:       true,
+			wantAllowed: true,
+		},
+		{
+			name:        "delete on Ready node is allowed",
+			fixture:     "delete-pod-on-ready-node.json",
+			armed:       true,
+			wantAllowed: true,
+		},
+		{
+			name:        "delete is allowed while interception is disabled",
+			fixture:     "delete-pod-on-notready-node.json",
+			armed:       false,
+			wantAllowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fixture(t, tt.fixture)
+			var req admissionv1.AdmissionReview
+			if err := json.Unmarshal(body, &req); err != nil {
+				t.Fatalf("invalid fixture: %v", err)
+			}
+
+			code, resp := review(t, newTestRouter(t, tt.armed, tt.interceptUpdates), body)
+			if code != http.StatusOK {
+				t.Fatalf("status code = %d, want %d", code, http.StatusOK)
+			}
+			if resp.Response == nil {
+				t.Fatal("response is missing")
+			}
+			if resp.Response.UID != req.Request.UID {
+				t.Errorf("response UID = %q, want %q", resp.Response.UID, req.Request.UID)
+			}
+			if resp.Response.Allowed != tt.wantAllowed {
+				t.Errorf("allowed = %v, want %v", resp.Response.Allowed, tt.wantAllowed)
+			}
+			if !tt.wantAllowed {
+				if resp.Response.Result == nil || resp.Response.Result.Code != tt.wantCode {
+					t.Fatalf("result = %+v, want code %d", resp.Response.Result, tt.wantCode)
+				}
+			}
+			if tt.wantRetryAfter > 0 {
+				details := resp.Response.Result.Details
+				if details == nil || details.RetryAfterSeconds != tt.wantRetryAfter {
+					t.Errorf("details = %+v, want retryAfterSeconds %d", details, tt.wantRetryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleAdmissionIgnoresOtherOperations(t *testing.T) {
+	code, resp := review(t, newTestRouter(t, true, nil), fixture(t, "create-pod.json"))
+	if code != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", code, http.StatusOK)
+	}
+	if resp.Response != nil && !resp.Response.Allowed {
+		t.Errorf("response = %+v, want the operation not to be intercepted", resp.Response)
+	}
+}
+
+func TestHandleAdmissionRejectsMalformedReview(t *testing.T) {
+	code, _ := review(t, newTestRouter(t, true, nil), []byte("{"))
+	if code != http.StatusBadRequest {
+		t.Errorf("status code = %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleAdmissionReportsDecisionLevel(t *testing.T) {
+	router := newTestRouter(t, true, nil)
+
+	_, resp := review(t, router, fixture(t, "delete-ignored-pod-on-notready-node.json"))
+	if !resp.Response.Allowed {
+		t.Fatal("pod annotated with mode ignore was intercepted")
+	}
+	if want := "at pod level"; resp.Response.Result == nil || !strings.Contains(resp.Response.Result.Message, want) {
+		t.Errorf("result = %+v, want a message containing %q", resp.Response.Result, want)
+	}
+
+	_, resp = review(t, router, fixture(t, "delete-pod-on-notready-node.json"))
+	if want := "intercepted at pool level"; resp.Response.Result == nil || !strings.Contains(resp.Response.Result.Message, want) {
+		t.Errorf("result = %+v, want a message containing %q", resp.Response.Result, want)
+	}
+}
+
+func TestHandleAdmissionAuditMode(t *testing.T) {
+	router := newTestRouterWithConfig(t, true, &config.Config{
+		NodePools: []config.NodePoolConfig{{
+			Name: "canary",
+			LabelSelector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "kubernetes.io/hostname",
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{"node-1", "node-2"},
+			}}},
+			Threshold: intstr.FromInt(2),
+			Window:    config.Duration{Duration: 5 * time.Minute},
+			Mode:      config.PoolModeAudit,
+		}},
+		DefaultThreshold: intstr.FromInt(2),
+		DefaultWindow:    5 * time.Minute,
+	})
+
+	for _, name := range []string{"delete-pod-on-notready-node.json", "evict-pod-on-notready-node.json"} {
+		t.Run(name, func(t *testing.T) {
+			before := testutil.ToFloat64(evictionWouldInterceptTotal.WithLabelValues(
+				operationOf(t, name), "shop", "canary", monitor.ReasonThresholdReached))
+
+			_, resp := review(t, router, fixture(t, name))
+			if !resp.Response.Allowed {
+				t.Fatalf("request intercepted in audit mode: %+v", resp.Response.Result)
+			}
+			if len(resp.Response.Warnings) != 1 || !strings.Contains(resp.Response.Warnings[0], "would be intercepted on node pool canary (ThresholdReached)") {
+				t.Errorf("warnings = %q", resp.Response.Warnings)
+			}
+
+			after := testutil.ToFloat64(evictionWouldInterceptTotal.WithLabelValues(
+				operationOf(t, name), "shop", "canary", monitor.ReasonThresholdReached))
+			if after != before+1 {
+				t.Errorf("would_intercept counter = %v, want %v", after, before+1)
+			}
+
+			// Dry runs have no side effects
+			var req admissionv1.AdmissionReview
+			if err := json.Unmarshal(fixture(t, name), &req); err != nil {
+				t.Fatalf("invalid fixture: %v", err)
+			}
+			dryRun := true
+			req.Request.DryRun = &dryRun
+			body, err := json.Marshal(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, resp := review(t, router, body); !resp.Response.Allowed {
+				t.Fatalf("dry run intercepted in audit mode: %+v", resp.Response.Result)
+			}
+			if got := testutil.ToFloat64(evictionWouldInterceptTotal.WithLabelValues(
+				operationOf(t, name), "shop", "canary", monitor.ReasonThresholdReached)); got != after {
+				t.Errorf("would_intercept counter = %v after a dry run, want %v", got, after)
+			}
+		})
+	}
+}
+
+// operationOf returns the admission operation of a fixture
+func operationOf(t *testing.T, name string) string {
+	t.Helper()
+	var req admissionv1.AdmissionReview
+	if err := json.Unmarshal(fixture(t, name), &req); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+	return string(req.Request.Operation)
+}
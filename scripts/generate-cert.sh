@@ -29,6 +29,11 @@ DNS.1 = pod-eviction-protection
 DNS.2 = pod-eviction-protection.default
 DNS.3 = pod-eviction-protection.default.svc
 DNS.4 = pod-eviction-protection.default.svc.cluster.local
+# The admin Service serving the callback API shares the certificate
+DNS.5 = pod-eviction-protection-admin
+DNS.6 = pod-eviction-protection-admin.default
+DNS.7 = pod-eviction-protection-admin.default.svc
+DNS.8 = pod-eviction-protection-admin.default.svc.cluster.local
 EOF
 
 # Generate CSR with SANs